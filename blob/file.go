@@ -0,0 +1,123 @@
+// Package blob stores files addressed by the SHA-256 of their content, the
+// same content is only stored once.
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// FileStore keeps blobs as files under a directory, each in a subdirectory
+// named after the first two characters of its key.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir}, nil
+}
+
+// Put stores the content of r and returns its key, the hex encoded SHA-256
+// of the content, and its size. Content already stored isn't written again.
+// Nothing is stored if reading r fails.
+func (s *FileStore) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(r, hash))
+	if err != nil {
+		_ = tmp.Close()
+		return "", 0, err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return "", 0, err
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(key)
+
+	_, err = os.Stat(path)
+	if err == nil {
+		return key, size, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+// Open returns the content stored under key, the caller must close it.
+func (s *FileStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Blob not found"}
+	}
+
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Blob not found"}
+		default:
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+// Delete removes the content stored under key, deleting a missing blob
+// isn't an error.
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// validKey reports whether key is a hex encoded SHA-256, so that no key
+// resolves to a path outside the store's directory.
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
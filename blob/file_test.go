@@ -0,0 +1,73 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, size, err := store.Put(ctx, strings.NewReader("receipt photo"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// sha256sum of "receipt photo"
+	const expectedKey = "c6b28a9ca5c9b2e7301c20fe3d74145f5dae20fcaea73f1e7cf10c79f5acfaa1"
+	if key != expectedKey {
+		t.Errorf("expected key %s. got %s", expectedKey, key)
+	}
+	if size != int64(len("receipt photo")) {
+		t.Errorf("expected size %d. got %d", len("receipt photo"), size)
+	}
+
+	again, _, err := store.Put(ctx, strings.NewReader("receipt photo"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != key {
+		t.Errorf("expected the same content to get the same key %s. got %s", key, again)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(store.dir, key[:2]))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the content to be stored once. got %d files", len(entries))
+	}
+
+	content, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := io.ReadAll(content)
+	content.Close()
+	if string(b) != "receipt photo" {
+		t.Errorf("expected content %q. got %q", "receipt photo", b)
+	}
+
+	err = store.Delete(ctx, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = store.Open(ctx, key)
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected deleted content not to be found. got %v", err)
+	}
+
+	_, err = store.Open(ctx, "../"+key[3:])
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected keys that aren't a SHA-256 not to be found. got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gmr458/receipt-processor/csvimport"
+	"github.com/gmr458/receipt-processor/env"
+	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/sqlite"
+)
+
+func importCSV(ctx context.Context, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dsn := fs.String("dsn", env.GetenvOrDefault("DSN", ""), "SQLite database file")
+	path := fs.String("file", "", "CSV file to import")
+	reportPath := fs.String("errors", "", "Write the error report as CSV to this file")
+	rulesetPath := fs.String("ruleset", env.GetenvOrDefault("RULESET_PATH", ""), "Current ruleset file, the built-in one if empty")
+	categoriesPath := fs.String("categories", env.GetenvOrDefault("CATEGORIES_PATH", ""), "Category dictionary file, the built-in one if empty")
+	batchSize := fs.Int("batch", 500, "Number of receipts stored per transaction")
+	duplicates := fs.String("duplicates", env.GetenvOrDefault("DUPLICATE_POLICY", "reject"), "Duplicate policy: reject, zero or flag")
+	_ = fs.Parse(args)
+
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("-batch: it should be at least 1, got %d", *batchSize)
+	}
+
+	current := receipt.DefaultRuleset()
+	if *rulesetPath != "" {
+		var err error
+		current, err = receipt.LoadRuleset(*rulesetPath)
+		if err != nil {
+			return fmt.Errorf("ruleset %q: %w", *rulesetPath, err)
+		}
+	}
+	rulesets, err := receipt.NewRulesets(current, receipt.DefaultRuleset())
+	if err != nil {
+		return err
+	}
+
+	duplicatePolicy, err := receipt.ParseDuplicatePolicy(*duplicates)
+	if err != nil {
+		return err
+	}
+
+	validationPolicy, err := loadValidationPolicy()
+	if err != nil {
+		return err
+	}
+
+	classifier := receipt.DefaultClassifier()
+	if *categoriesPath != "" {
+		classifier, err = receipt.LoadClassifier(*categoriesPath)
+		if err != nil {
+			return fmt.Errorf("category dictionary %q: %w", *categoriesPath, err)
+		}
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	conn, err := sqlite.NewConn(*dsn, logger, time.Minute)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	repository := sqlite.NewRepository(conn)
+	service := receipt.NewService(repository.Receipt, repository.Campaign, nopCache{}, rulesets, classifier, duplicatePolicy, validationPolicy)
+
+	report, err := csvimport.Import(ctx, &service, file, *batchSize)
+	if err != nil {
+		return err
+	}
+
+	if *reportPath != "" {
+		err = writeErrorReport(*reportPath, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	return enc.Encode(report)
+}
+
+// loadValidationPolicy reads the validation policy from the same environment
+// variables as the webservice, so imported receipts pass the same checks.
+func loadValidationPolicy() (receipt.ValidationPolicy, error) {
+	policy := receipt.DefaultValidationPolicy()
+
+	policy.RetailerMaxLength = env.GetenvOrDefault("RETAILER_MAX_LENGTH", policy.RetailerMaxLength)
+	policy.DescriptionMaxLength = env.GetenvOrDefault("DESCRIPTION_MAX_LENGTH", policy.DescriptionMaxLength)
+	policy.MaxItems = env.GetenvOrDefault("RECEIPT_MAX_ITEMS", policy.MaxItems)
+	policy.AllowFuturePurchases = env.GetenvOrDefault("ALLOW_FUTURE_PURCHASES", policy.AllowFuturePurchases)
+	policy.MaxPurchaseAgeDays = env.GetenvOrDefault("PURCHASE_MAX_AGE_DAYS", policy.MaxPurchaseAgeDays)
+
+	err := policy.Validate()
+	if err != nil {
+		return receipt.ValidationPolicy{}, fmt.Errorf("validation policy: %w", err)
+	}
+
+	classes, err := receipt.ParseCharacterClasses(
+		env.GetenvOrDefault("RETAILER_CHARACTERS", "letters digits spaces punctuation symbols"),
+	)
+	if err != nil {
+		return receipt.ValidationPolicy{}, fmt.Errorf("RETAILER_CHARACTERS: %w", err)
+	}
+	policy.RetailerCharacters = classes
+
+	return policy, nil
+}
+
+func writeErrorReport(path string, report csvimport.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = report.WriteErrors(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+var errNotCached = errors.New("not cached")
+
+// nopCache stands in for the redis cache, the CLI writes straight to the
+// database and has nothing to cache.
+type nopCache struct{}
+
+func (nopCache) SetPaginatedReceipts(context.Context, string, receipt.PaginatedReceipts, time.Duration) error {
+	return nil
+}
+
+func (nopCache) GetPaginatedReceipts(context.Context, string) (receipt.PaginatedReceipts, error) {
+	return receipt.PaginatedReceipts{}, errNotCached
+}
+
+func (nopCache) GetScoreById(context.Context, string) (receipt.Score, error) {
+	return receipt.Score{}, errNotCached
+}
+
+func (nopCache) SetScoreById(context.Context, string, receipt.Score, time.Duration) error {
+	return nil
+}
+
+func (nopCache) GetReceiptById(context.Context, string) (*receipt.Receipt, error) {
+	return nil, errNotCached
+}
+
+func (nopCache) SetReceiptById(context.Context, *receipt.Receipt, time.Duration) error {
+	return nil
+}
+
+func (nopCache) DeleteReceiptById(context.Context, string) error {
+	return nil
+}
+
+func (nopCache) DeletePaginatedReceipts(context.Context) error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+
+	"github.com/gmr458/receipt-processor/env"
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/sqlite"
+)
+
+var version string
+
+const usage = `Usage: receiptctl <command> [flags]
+
+Commands:
+  simulate     Compare the points of every stored receipt under a candidate ruleset
+  import       Import receipts from a CSV file
+  fingerprint  Fingerprint the receipts stored before duplicate detection
+  version      Display version
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Logs go to stderr so stdout only carries command output.
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "simulate":
+		err = simulate(ctx, logger, os.Args[2:])
+	case "import":
+		err = importCSV(ctx, logger, os.Args[2:])
+	case "fingerprint":
+		err = fingerprint(ctx, logger, os.Args[2:])
+	case "version":
+		fmt.Printf("version: %s\n", version)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logger.Error(os.Args[1]+" failed", "error", err, "details", errs.ErrorDetails(err))
+		os.Exit(1)
+	}
+}
+
+func simulate(ctx context.Context, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	dsn := fs.String("dsn", env.GetenvOrDefault("DSN", ""), "SQLite database file")
+	candidatePath := fs.String("ruleset", "", "Candidate ruleset file")
+	currentPath := fs.String("current", env.GetenvOrDefault("RULESET_PATH", ""), "Current ruleset file, the built-in one if empty")
+	top := fs.Int("top", 10, "Number of most changed receipts to report")
+	buckets := fs.Int("buckets", 20, "Number of histogram buckets")
+	_ = fs.Parse(args)
+
+	if *candidatePath == "" {
+		return fmt.Errorf("-ruleset is required")
+	}
+
+	candidate, err := receipt.LoadRuleset(*candidatePath)
+	if err != nil {
+		return fmt.Errorf("ruleset %q: %w", *candidatePath, err)
+	}
+
+	current := receipt.DefaultRuleset()
+	if *currentPath != "" {
+		current, err = receipt.LoadRuleset(*currentPath)
+		if err != nil {
+			return fmt.Errorf("ruleset %q: %w", *currentPath, err)
+		}
+	}
+
+	conn, err := sqlite.NewConn(*dsn, logger, time.Minute)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	repository := sqlite.NewRepository(conn)
+	report, err := receipt.Simulate(
+		ctx,
+		repository.Receipt,
+		current,
+		candidate,
+		receipt.SimulationOptions{Top: *top, Buckets: *buckets},
+	)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	return enc.Encode(report)
+}
+
+func fingerprint(ctx context.Context, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("fingerprint", flag.ExitOnError)
+	dsn := fs.String("dsn", env.GetenvOrDefault("DSN", ""), "SQLite database file")
+	_ = fs.Parse(args)
+
+	conn, err := sqlite.NewConn(*dsn, logger, time.Minute)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	repository := sqlite.NewRepository(conn)
+	updated, err := repository.Receipt.(sqlite.ReceiptRepository).BackfillFingerprints(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("receipts fingerprinted", "updated", updated)
+	return nil
+}
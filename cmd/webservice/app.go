@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"sync"
@@ -11,37 +12,80 @@ import (
 	"github.com/gmr458/receipt-processor/receipt"
 	"github.com/gmr458/receipt-processor/redis"
 	"github.com/gmr458/receipt-processor/sqlite"
+	"github.com/gmr458/receipt-processor/webhook"
 )
 
 type app struct {
-	config         config
-	logger         *slog.Logger
-	server         *http.Server
-	debugServer    *http.Server
-	receiptService receipt.Service
-	wg             sync.WaitGroup
-	corsHandler    *cors.Cors
-	rateLimiter    *redis.TokenBucket
+	config            config
+	logger            *slog.Logger
+	server            *http.Server
+	debugServer       *http.Server
+	receiptService    receipt.Service
+	campaignService   receipt.CampaignService
+	jobService        receipt.JobService
+	attachmentService receipt.AttachmentService
+	webhookService    webhook.Service
+	dispatcher        webhook.Dispatcher
+	wg                sync.WaitGroup
+	jobsQueued        chan struct{}
+	stopWorkers       context.CancelFunc
+	corsHandler       *cors.Cors
+	rateLimiter       *redis.TokenBucket
+	idempotencyStore  *redis.IdempotencyStore
 }
 
-func newApp(cfg config, logger *slog.Logger, sqliteConn *sqlite.Conn, redisClient *goredis.Client) *app {
+func newApp(
+	cfg config,
+	logger *slog.Logger,
+	sqliteConn *sqlite.Conn,
+	redisClient *goredis.Client,
+	blobStore receipt.BlobStore,
+	rulesets receipt.Rulesets,
+	classifier receipt.Classifier,
+) *app {
 	repository := sqlite.NewRepository(sqliteConn)
 	cache := redis.NewCache(redisClient)
+	receiptService := receipt.NewService(
+		repository.Receipt,
+		repository.Campaign,
+		cache.Receipt,
+		rulesets,
+		classifier,
+		cfg.duplicates.policy,
+		cfg.validation.policy,
+	)
+	attachmentService := receipt.NewAttachmentService(
+		repository.Attachment,
+		repository.Receipt,
+		blobStore,
+		int64(cfg.attachments.maxBytes),
+	)
+	dispatcher := webhook.NewDispatcher(
+		repository.Webhook,
+		&http.Client{Timeout: cfg.webhooks.timeout},
+		cfg.webhooks.maxAttempts,
+		cfg.webhooks.retryBackoff,
+		cfg.webhooks.concurrency,
+	)
 
 	return &app{
-		config: cfg,
-		logger: logger,
-		receiptService: receipt.NewService(
-			repository.Receipt,
-			cache.Receipt,
-		),
+		config:            cfg,
+		logger:            logger,
+		receiptService:    receiptService,
+		campaignService:   receipt.NewCampaignService(repository.Campaign),
+		jobService:        receipt.NewJobService(repository.Job, receiptService),
+		attachmentService: attachmentService,
+		webhookService:    webhook.NewService(repository.Webhook),
+		dispatcher:        dispatcher,
+		jobsQueued:        make(chan struct{}, cfg.jobs.workers),
 		corsHandler: cors.New(cors.Options{
 			AllowedOrigins:   cfg.cors.trustedOrigins,
 			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
-			AllowedHeaders:   []string{"Authorization", "Content-Type"},
+			AllowedHeaders:   []string{"Authorization", "Content-Type", "Idempotency-Key"},
 			AllowCredentials: false,
 			MaxAge:           300,
 		}),
-		rateLimiter: redis.NewTokenBucket(redisClient, cfg.limiter.rps, cfg.limiter.burst),
+		rateLimiter:      redis.NewTokenBucket(redisClient, cfg.limiter.rps, cfg.limiter.burst),
+		idempotencyStore: redis.NewIdempotencyStore(redisClient, cfg.idempotency.ttl),
 	}
 }
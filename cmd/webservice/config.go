@@ -1,5 +1,11 @@
 package main
 
+import (
+	"time"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
 type config struct {
 	// HTTP Server's host
 	host string
@@ -31,6 +37,105 @@ type config struct {
 		db int
 	}
 
+	// Scoring Rules Config
+	rules struct {
+		// Path to the current JSON or YAML ruleset file, the built-in
+		// ruleset is used if empty
+		path string
+
+		// Directory of historical JSON or YAML ruleset files, receipts
+		// scored with any of these versions can still be explained and
+		// rescored
+		historyDir string
+
+		// Path to the JSON keyword dictionary used to classify items, the
+		// built-in dictionary is used if empty
+		categoriesPath string
+	}
+
+	// Duplicate Detection Config
+	duplicates struct {
+		// What to do with receipts matching one already stored: reject, zero
+		// or flag
+		policy receipt.DuplicatePolicy
+	}
+
+	// Validation Config
+	validation struct {
+		// Limits receipts and receipt listings are validated against
+		policy receipt.ValidationPolicy
+	}
+
+	// Batch Processing Config
+	batch struct {
+		// Maximum number of receipts accepted by a single batch request
+		maxSize int
+	}
+
+	// Streaming Ingestion Config
+	stream struct {
+		// Number of receipts processed per transaction by streaming requests
+		chunkSize int
+	}
+
+	// CSV Import Config
+	csvImport struct {
+		// Maximum size in bytes of an uploaded CSV file
+		maxBytes int
+
+		// Number of imported receipts stored per transaction
+		batchSize int
+	}
+
+	// Attachments Config
+	attachments struct {
+		// Directory of the blob store keeping the attached files
+		dir string
+
+		// Maximum size in bytes of an attached file
+		maxBytes int
+	}
+
+	// Asynchronous Processing Config
+	jobs struct {
+		// Number of workers processing queued jobs
+		workers int
+
+		// How often idle workers look for queued jobs, they are also woken
+		// up when a job is submitted
+		pollInterval time.Duration
+	}
+
+	// Webhooks Config
+	webhooks struct {
+		// How often outbox events are dispatched and due deliveries sent
+		pollInterval time.Duration
+
+		// Number of attempts made to send a delivery before it's dead
+		maxAttempts int
+
+		// Wait after the first failed attempt, doubled after each next one
+		retryBackoff time.Duration
+
+		// Timeout of a request sending a delivery
+		timeout time.Duration
+
+		// Number of deliveries sent at the same time
+		concurrency int
+	}
+
+	// Idempotency Config
+	idempotency struct {
+		// How long responses to requests with an Idempotency-Key are kept
+		ttl time.Duration
+	}
+
+	// Admin Config
+	admin struct {
+		// Bearer token required by admin endpoints, they are disabled if empty
+		token string
+	}
+
 	// Limit Rate Config
 	limiter struct {
 		enabled            bool
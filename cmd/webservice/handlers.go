@@ -1,13 +1,68 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/parser"
 	"github.com/gmr458/receipt-processor/receipt"
 )
 
 func (app *app) handlerProcessReceipts(w http.ResponseWriter, r *http.Request) {
+	async, err := getURLValueBool(r.URL.Query(), "async")
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	parse, err := getURLValueBool(r.URL.Query(), "parse")
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
 	var input receipt.ReceiptDTO
+	data := envelope{}
+
+	if parse {
+		text, err := app.readText(w, r)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+
+		parsed := parser.Parse(text)
+		input = parsed.Receipt
+		data["parsed"] = parsed
+	} else {
+		err = app.readJSON(w, r, &input)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+	}
+
+	if async {
+		app.submitJob(w, r, input, data)
+		return
+	}
+
+	receipt, err := app.receiptService.Process(r.Context(), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	data["id"] = receipt.ID
+	app.sendJSON(w, http.StatusCreated, data, nil)
+}
+
+func (app *app) handlerProcessReceiptsBatch(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Receipts []receipt.ReceiptDTO `json:"receipts"`
+	}
 
 	err := app.readJSON(w, r, &input)
 	if err != nil {
@@ -15,37 +70,234 @@ func (app *app) handlerProcessReceipts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	receipt, err := app.receiptService.Process(r.Context(), input)
+	if len(input.Receipts) > app.config.batch.maxSize {
+		app.badRequest(w, "Invalid field/s", map[string][]string{
+			"receipts": {fmt.Sprintf("a batch can hold at most %d receipts", app.config.batch.maxSize)},
+		})
+		return
+	}
+
+	results, err := app.receiptService.ProcessBatch(r.Context(), input.Receipts)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	created, failed := receipt.BatchResults(results)
+	app.sendJSON(w, http.StatusOK, envelope{
+		"created": created,
+		"failed":  failed,
+		"results": results,
+	}, nil)
+}
+
+func (app *app) handlerScoreReceipt(w http.ResponseWriter, r *http.Request) {
+	var input receipt.ReceiptDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("rulesetVersion")
+
+	breakdown, err := app.receiptService.Score(r.Context(), input, version)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, breakdown, nil)
+}
+
+// handlerGetReceipt also serves HEAD requests, the mux routes them to GET
+// handlers and the server drops the body.
+func (app *app) handlerGetReceipt(w http.ResponseWriter, r *http.Request) {
+	receipt, err := app.receiptService.GetById(r.Context(), r.PathValue("id"), app.isAdmin(r))
 	if err != nil {
 		app.errorResponse(w, r, err)
 		return
 	}
 
-	app.sendJSON(w, http.StatusCreated, envelope{
-		"id": receipt.ID,
+	headers := make(http.Header)
+	headers.Set("Last-Modified", receipt.UpdatedAt.UTC().Format(http.TimeFormat))
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"receipt": receipt,
+	}, headers)
+}
+
+func (app *app) handlerUpdateReceipt(w http.ResponseWriter, r *http.Request) {
+	var input receipt.ReceiptDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	receipt, err := app.receiptService.Update(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"receipt": receipt,
+	}, nil)
+}
+
+// handlerPatchReceipt takes a JSON Merge Patch of the receipt as submitted,
+// such as {"total": "12.00", "items": [...]}.
+func (app *app) handlerPatchReceipt(w http.ResponseWriter, r *http.Request) {
+	var input map[string]any
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	receipt, err := app.receiptService.Patch(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"receipt": receipt,
+	}, nil)
+}
+
+func (app *app) handlerDeleteReceipt(w http.ResponseWriter, r *http.Request) {
+	var input receipt.DeletionDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	err = app.receiptService.Delete(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerReceiptAction serves the custom methods of a receipt, such as
+// POST /receipts/{id}:restore. Restoring and purging are reserved to admins.
+// The mux can't match a wildcard followed by text in the same segment, so the
+// segment is split here.
+func (app *app) handlerReceiptAction(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(r.PathValue("idAction"), ":")
+
+	switch action {
+	case "restore":
+		if !app.isAdmin(r) {
+			app.errorResponse(w, r, &errs.Error{
+				Code:    errs.EUNAUTHORIZED,
+				Message: "A valid admin token is required",
+			})
+			return
+		}
+
+		receipt, err := app.receiptService.Restore(r.Context(), id)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+
+		app.sendJSON(w, http.StatusOK, envelope{
+			"receipt": receipt,
+		}, nil)
+
+	case "purge":
+		if !app.isAdmin(r) {
+			app.errorResponse(w, r, &errs.Error{
+				Code:    errs.EUNAUTHORIZED,
+				Message: "A valid admin token is required",
+			})
+			return
+		}
+
+		attachments, err := app.receiptService.Purge(r.Context(), id)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+
+		// The receipt is gone already, content left behind only takes space.
+		err = app.attachmentService.DeleteContent(r.Context(), attachments)
+		if err != nil {
+			app.logError(r, err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		app.errorResponse(w, r, &errs.Error{Code: errs.ENOTFOUND, Message: "Not found"})
+	}
+}
+
+func (app *app) handlerGetReceiptRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := app.receiptService.GetRevisions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"revisions": revisions,
 	}, nil)
 }
 
 func (app *app) handlerGetPoints(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
-		app.badRequest(w, "Invalid path value", map[string]string{
-			"id": "id cannot be an empty string",
+		app.badRequest(w, "Invalid path value", map[string][]string{
+			"id": {"id cannot be an empty string"},
 		})
 		return
 	}
 
-	points, err := app.receiptService.GetPointsById(r.Context(), id)
+	version := r.URL.Query().Get("rulesetVersion")
+
+	score, err := app.receiptService.GetPointsById(r.Context(), id, version, app.isAdmin(r))
 	if err != nil {
 		app.errorResponse(w, r, err)
 		return
 	}
 
 	app.sendJSON(w, http.StatusOK, envelope{
-		"points": points,
+		"points":         score.Points,
+		"rulesetVersion": score.RulesetVersion,
 	}, nil)
 }
 
+func (app *app) handlerGetPointsBreakdown(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		app.badRequest(w, "Invalid path value", map[string][]string{
+			"id": {"id cannot be an empty string"},
+		})
+		return
+	}
+
+	version := r.URL.Query().Get("rulesetVersion")
+
+	breakdown, err := app.receiptService.GetPointsBreakdownById(r.Context(), id, version, app.isAdmin(r))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, breakdown, nil)
+}
+
 func (app *app) handlerGetReceipts(w http.ResponseWriter, r *http.Request) {
 	queryValues := r.URL.Query()
 	filters := receipt.NewFilters(
@@ -53,14 +305,32 @@ func (app *app) handlerGetReceipts(w http.ResponseWriter, r *http.Request) {
 		"-id",
 		"retailer",
 		"-retailer",
+		"purchased_at",
+		"-purchased_at",
 		"purchase_date",
 		"-purchase_date",
 		"total",
 		"-total",
+		"created_at",
+		"-created_at",
 	)
 	filters.Page = getURLValuePositiveInt(queryValues, "page", 1)
 	filters.Limit = getURLValuePositiveInt(queryValues, "limit", 10)
-	filters.Sort = getURLValueStr(queryValues, filters.SortSafeList, "sort", "purchase_date")
+	filters.Sort = getURLValueStr(queryValues, filters.SortSafeList, "sort", "purchased_at")
+
+	includeDeleted, err := getURLValueBool(queryValues, "includeDeleted")
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+	if includeDeleted && !app.isAdmin(r) {
+		app.errorResponse(w, r, &errs.Error{
+			Code:    errs.EUNAUTHORIZED,
+			Message: "A valid admin token is required to include deleted receipts",
+		})
+		return
+	}
+	filters.IncludeDeleted = includeDeleted
 
 	paginatedReceipts, err := app.receiptService.GetReceipts(r.Context(), filters)
 	if err != nil {
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+func (app *app) handlerSimulateRuleset(w http.ResponseWriter, r *http.Request) {
+	var candidate receipt.Ruleset
+
+	err := app.readJSON(w, r, &candidate)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	queryValues := r.URL.Query()
+	opts := receipt.SimulationOptions{
+		Top:     getURLValuePositiveInt(queryValues, "top", 10),
+		Buckets: getURLValuePositiveInt(queryValues, "buckets", 20),
+	}
+
+	// Simulations go through every stored receipt and can outlast the
+	// server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(5 * time.Minute))
+
+	report, err := app.receiptService.Simulate(r.Context(), candidate, opts)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, report, nil)
+}
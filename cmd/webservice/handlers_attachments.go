@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// multipartOverhead is the room left in the body limit for the multipart
+// headers and boundaries around an attached file.
+const multipartOverhead = 64 << 10
+
+// handlerUploadAttachment attaches the file uploaded in the file field of a
+// multipart form to the receipt. Uploading a file the receipt already has
+// responds with the existing attachment.
+func (app *app) handlerUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(app.config.attachments.maxBytes+multipartOverhead))
+
+	file, err := multipartFile(r, "file")
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	attachment, created, err := app.attachmentService.Upload(r.Context(), r.PathValue("id"), file.FileName(), file)
+	if err != nil {
+		app.errorResponse(w, r, bodyLimitError(err))
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", "/receipts/"+attachment.ReceiptID+"/attachments/"+attachment.ID)
+
+	app.sendJSON(w, status, envelope{
+		"attachment": attachment,
+	}, headers)
+}
+
+// handlerGetAttachment sends the attached file as stored, HEAD requests get
+// its headers only.
+func (app *app) handlerGetAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, content, err := app.attachmentService.Open(r.Context(), r.PathValue("id"), r.PathValue("aid"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename})
+	if attachment.Filename == "" || disposition == "" {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+	w.Header().Set("Last-Modified", attachment.CreatedAt.Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, err = io.Copy(w, content)
+	if err != nil {
+		app.logError(r, err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+func (app *app) handlerCreateCampaign(w http.ResponseWriter, r *http.Request) {
+	var input receipt.CampaignDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	campaign, err := app.campaignService.Create(r.Context(), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusCreated, envelope{
+		"campaign": campaign,
+	}, nil)
+}
+
+func (app *app) handlerGetCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := app.campaignService.GetCampaigns(r.Context())
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"campaigns": campaigns,
+	}, nil)
+}
+
+func (app *app) handlerGetCampaign(w http.ResponseWriter, r *http.Request) {
+	campaign, err := app.campaignService.GetById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"campaign": campaign,
+	}, nil)
+}
+
+func (app *app) handlerUpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	var input receipt.CampaignDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	campaign, err := app.campaignService.Update(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"campaign": campaign,
+	}, nil)
+}
+
+func (app *app) handlerDeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	err := app.campaignService.Delete(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gmr458/receipt-processor/csvimport"
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// handlerImportReceipts imports the CSV uploaded in the file field of a
+// multipart form. The report is JSON, or with ?format=csv the error report as
+// a CSV download.
+func (app *app) handlerImportReceipts(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(app.config.csvImport.maxBytes))
+
+	file, err := multipartFile(r, "file")
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	report, err := csvimport.Import(r.Context(), &app.receiptService, file, app.config.csvImport.batchSize)
+	if err != nil {
+		app.errorResponse(w, r, bodyLimitError(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="import-errors.csv"`)
+		err = report.WriteErrors(w)
+		if err != nil {
+			app.logError(r, err)
+		}
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, report, nil)
+}
+
+// multipartFile returns the first part of the multipart body named field. The
+// part is streamed, nothing is buffered to memory or disk.
+func multipartFile(r *http.Request, field string) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, errs.Errorf(errs.EINVALID, "body must be multipart/form-data")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errs.Errorf(errs.EINVALID, "body must contain a %s field", field)
+		}
+		if err != nil {
+			err = bodyLimitError(err)
+			if errs.ErrorCode(err) == errs.EINTERNAL {
+				err = errs.Errorf(errs.EINVALID, "body contains badly-formed multipart data")
+			}
+			return nil, err
+		}
+
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
+
+// bodyLimitError turns errors from reading past the body limit into a client
+// error, other errors are returned as is.
+func bodyLimitError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return errs.Errorf(errs.EINVALID, "body must not be larger than %d bytes", maxBytesError.Limit)
+	}
+
+	return err
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+// submitJob queues input for the job workers and responds with the job added
+// to data, the receipt is validated once a worker runs it.
+func (app *app) submitJob(w http.ResponseWriter, r *http.Request, input receipt.ReceiptDTO, data envelope) {
+	job, err := app.jobService.Submit(r.Context(), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.notifyJobQueued()
+
+	headers := make(http.Header)
+	headers.Set("Location", "/jobs/"+job.ID)
+
+	data["job"] = job
+	app.sendJSON(w, http.StatusAccepted, data, headers)
+}
+
+func (app *app) handlerGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := app.jobService.GetById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"job": job,
+	}, nil)
+}
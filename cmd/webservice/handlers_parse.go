@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/parser"
+)
+
+// handlerParseReceipt reads the plain text of a receipt and responds with the
+// parsed DTO and how confident each field is, nothing is stored.
+func (app *app) handlerParseReceipt(w http.ResponseWriter, r *http.Request) {
+	text, err := app.readText(w, r)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, parser.Parse(text), nil)
+}
+
+func (app *app) readText(w http.ResponseWriter, r *http.Request) (string, error) {
+	const maxBytes = 1_048_576
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", bodyLimitError(err)
+	}
+
+	switch {
+	case len(b) == 0:
+		return "", errs.Errorf(errs.EINVALID, "body must not be empty")
+	case !utf8.Valid(b):
+		return "", errs.Errorf(errs.EINVALID, "body must be UTF-8 text")
+	}
+
+	return string(b), nil
+}
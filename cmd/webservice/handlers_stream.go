@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+const (
+	// maxStreamLineBytes caps a single NDJSON line, the same limit readJSON
+	// puts on a whole body.
+	maxStreamLineBytes = 1_048_576
+
+	// streamIdleTimeout is how long a stream may wait for the next chunk to be
+	// read and its results written. Deadlines are pushed back after every
+	// chunk so the server timeouts don't cut long uploads.
+	streamIdleTimeout = time.Minute
+)
+
+// streamLine is a decoded NDJSON line waiting for its chunk to be processed.
+type streamLine struct {
+	line   int
+	dto    receipt.ReceiptDTO
+	errors map[string][]string
+}
+
+type streamResult struct {
+	Line   int                 `json:"line"`
+	ID     string              `json:"id,omitempty"`
+	Points *int                `json:"points,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"`
+}
+
+type streamSummary struct {
+	Done    bool `json:"done"`
+	Created int  `json:"created"`
+	Failed  int  `json:"failed"`
+}
+
+// handlerProcessReceiptsStream reads newline delimited receipts and processes
+// them in chunks of at most stream.chunkSize receipts, each chunk in a single
+// transaction. A result line is written for every input line, the last line
+// is a summary. Once the response has started errors can't change the
+// status, a fatal error is written as an error line and ends the stream.
+func (app *app) handlerProcessReceiptsStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	// Results are written while the body is still being read.
+	_ = rc.EnableFullDuplex()
+	app.extendStreamDeadlines(rc)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	summary := streamSummary{}
+	chunk := make([]streamLine, 0, app.config.stream.chunkSize)
+
+	flush := func() error {
+		results, err := app.processStreamChunk(r, chunk)
+		if err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+
+		for _, result := range results {
+			if result.Errors != nil {
+				summary.Failed++
+			} else {
+				summary.Created++
+			}
+
+			err = encoder.Encode(result)
+			if err != nil {
+				return err
+			}
+		}
+
+		app.extendStreamDeadlines(rc)
+		return rc.Flush()
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		chunk = append(chunk, decodeStreamLine(line, b))
+		if len(chunk) < app.config.stream.chunkSize {
+			continue
+		}
+
+		err := flush()
+		if err != nil {
+			app.streamError(encoder, r, err)
+			return
+		}
+	}
+
+	err := flush()
+	if err != nil {
+		app.streamError(encoder, r, err)
+		return
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = errs.Errorf(errs.EINVALID, "line %d must not be larger than %d bytes", line+1, maxStreamLineBytes)
+		}
+		app.streamError(encoder, r, err)
+		return
+	}
+
+	summary.Done = true
+	_ = encoder.Encode(summary)
+}
+
+// processStreamChunk processes the decoded lines of chunk as a batch, results
+// keep the order of the lines.
+func (app *app) processStreamChunk(r *http.Request, chunk []streamLine) ([]streamResult, error) {
+	results := make([]streamResult, len(chunk))
+	dtos := make([]receipt.ReceiptDTO, 0, len(chunk))
+	positions := make([]int, 0, len(chunk))
+
+	for i, line := range chunk {
+		results[i] = streamResult{Line: line.line, Errors: line.errors}
+		if line.errors == nil {
+			dtos = append(dtos, line.dto)
+			positions = append(positions, i)
+		}
+	}
+
+	if len(dtos) == 0 {
+		return results, nil
+	}
+
+	batch, err := app.receiptService.ProcessBatch(r.Context(), dtos)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range batch {
+		i := positions[result.Index]
+		results[i].ID = result.ID
+		results[i].Points = result.Points
+		results[i].Errors = result.Errors
+	}
+
+	return results, nil
+}
+
+func decodeStreamLine(line int, b []byte) streamLine {
+	decoded := streamLine{line: line}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&decoded.dto)
+	if err != nil {
+		err = decodeJSONError(err, "line")
+	} else if decoder.More() {
+		err = errs.Errorf(errs.EINVALID, "line must only contain a single JSON value")
+	}
+
+	if err != nil {
+		decoded.errors = map[string][]string{"line": {errs.ErrorMessage(err)}}
+	}
+
+	return decoded
+}
+
+func (app *app) extendStreamDeadlines(rc *http.ResponseController) {
+	deadline := time.Now().Add(streamIdleTimeout)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
+
+// streamError writes err as the last line of a stream.
+func (app *app) streamError(encoder *json.Encoder, r *http.Request, err error) {
+	if errs.ErrorCode(err) == errs.EINTERNAL {
+		app.logError(r, err)
+	}
+
+	_ = encoder.Encode(envelope{
+		"error":   errs.ErrorMessage(err),
+		"details": errs.ErrorDetails(err),
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gmr458/receipt-processor/webhook"
+)
+
+func (app *app) handlerCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input webhook.SubscriptionDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	subscription, err := app.webhookService.Create(r.Context(), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusCreated, envelope{
+		"webhook": subscription,
+	}, nil)
+}
+
+func (app *app) handlerGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := app.webhookService.GetSubscriptions(r.Context())
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"webhooks": subscriptions,
+	}, nil)
+}
+
+func (app *app) handlerGetWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, err := app.webhookService.GetById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"webhook": subscription,
+	}, nil)
+}
+
+func (app *app) handlerUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input webhook.SubscriptionDTO
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	subscription, err := app.webhookService.Update(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"webhook": subscription,
+	}, nil)
+}
+
+func (app *app) handlerDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := app.webhookService.Delete(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *app) handlerGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := app.webhookService.GetDeliveries(r.Context(), r.PathValue("id"))
+	if err != nil {
+		app.errorResponse(w, r, err)
+		return
+	}
+
+	app.sendJSON(w, http.StatusOK, envelope{
+		"deliveries": deliveries,
+	}, nil)
+}
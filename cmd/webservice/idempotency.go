@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/redis"
+)
+
+const maxIdempotencyKeyLen = 255
+
+// responseRecorder passes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.status == 0 {
+		rec.status = statusCode
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// idempotent replays the stored response to requests repeating the
+// Idempotency-Key of an earlier request from the same client, keys of
+// different clients never collide. Reusing a key for a different request is
+// a conflict. Responses with a 5xx status aren't stored so the request can be
+// retried.
+func (app *app) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLen {
+			app.badRequest(w, "Invalid header", map[string][]string{
+				"Idempotency-Key": {"Idempotency-Key max length is 255 characters"},
+			})
+			return
+		}
+
+		client, err := app.idempotencyClient(r)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+
+		const maxBytes = 1_048_576
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		if err != nil {
+			app.errorResponse(w, r, bodyLimitError(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = client + ":" + key
+		fingerprint := requestFingerprint(r, body)
+
+		stored, err := app.idempotencyStore.Begin(r.Context(), key, fingerprint)
+		if err != nil {
+			app.errorResponse(w, r, err)
+			return
+		}
+
+		if stored != nil {
+			app.replay(w, r, stored, fingerprint)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		ctx := context.WithoutCancel(r.Context())
+
+		// A panic must release the key, recoverPanic writes the response.
+		completed := false
+		defer func() {
+			if !completed {
+				_ = app.idempotencyStore.Abandon(ctx, key)
+			}
+		}()
+
+		next(rec, r)
+
+		if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+			return
+		}
+
+		completed = true
+		err = app.idempotencyStore.Complete(ctx, key, redis.StoredResponse{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Header:      rec.header,
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			app.logError(r, err)
+		}
+	}
+}
+
+// idempotencyClient identifies the client an Idempotency-Key belongs to: the
+// admin when the request carries the admin token, otherwise the client
+// address the rate limiter uses.
+func (app *app) idempotencyClient(r *http.Request) (string, error) {
+	if app.isAdmin(r) {
+		return "admin", nil
+	}
+
+	ip, err := clientIP(r, app.config.limiter.trustedProxyHeader)
+	if err != nil {
+		return "", err
+	}
+
+	return "ip:" + ip, nil
+}
+
+func (app *app) replay(w http.ResponseWriter, r *http.Request, stored *redis.StoredResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		app.errorResponse(w, r, &errs.Error{
+			Code:    errs.ECONFLICT,
+			Message: "Idempotency-Key was already used for a different request",
+		})
+		return
+	}
+
+	if stored.Status == 0 {
+		app.errorResponse(w, r, &errs.Error{
+			Code:    errs.ECONFLICT,
+			Message: "A request with this Idempotency-Key is being processed",
+		})
+		return
+	}
+
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.Status)
+	if _, err := w.Write(stored.Body); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// requestFingerprint identifies a request by its method, path and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
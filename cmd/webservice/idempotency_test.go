@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/gmr458/receipt-processor/redis"
+)
+
+func TestIdempotentClients(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	app := &app{
+		logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+		idempotencyStore: redis.NewIdempotencyStore(client, time.Hour),
+	}
+
+	processed := 0
+	handler := app.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		processed++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(strconv.Itoa(processed)))
+	})
+
+	tests := []struct {
+		name     string
+		client   string
+		body     string
+		status   int
+		response string
+		replayed bool
+	}{
+		{"first client", "10.0.0.1:5000", `{"a":1}`, http.StatusCreated, "1", false},
+		{"second client with the same request", "10.0.0.2:5000", `{"a":1}`, http.StatusCreated, "2", false},
+		{"first client again", "10.0.0.1:6000", `{"a":1}`, http.StatusCreated, "1", true},
+		{"third client with another request", "10.0.0.3:5000", `{"a":2}`, http.StatusCreated, "3", false},
+		{"first client with another request", "10.0.0.1:5000", `{"a":2}`, http.StatusConflict, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/receipts/process", strings.NewReader(test.body))
+			r.RemoteAddr = test.client
+			r.Header.Set("Idempotency-Key", "same-key")
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("expected status %d. got %d, %s", test.status, w.Code, w.Body.String())
+			}
+			if test.response != "" && w.Body.String() != test.response {
+				t.Errorf("expected response %q. got %q", test.response, w.Body.String())
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != test.replayed {
+				t.Errorf("expected replayed to be %t. got %t", test.replayed, replayed)
+			}
+		})
+	}
+}
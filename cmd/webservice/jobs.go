@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// startWorkers puts back in the queue the jobs interrupted by the previous
+// shutdown and starts the job workers and the webhook dispatcher. Workers
+// stop claiming jobs once stopWorkers is called, the job being processed is
+// finished first.
+func (app *app) startWorkers() error {
+	requeued, err := app.jobService.Requeue(context.Background())
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		app.logger.Info("interrupted jobs requeued", "jobs", requeued)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopWorkers = cancel
+
+	for range app.config.jobs.workers {
+		app.wg.Add(1)
+		go app.worker(ctx)
+	}
+
+	app.logger.Info("job workers started", "workers", app.config.jobs.workers)
+
+	app.wg.Add(1)
+	go app.dispatchWebhooks(ctx)
+
+	return nil
+}
+
+func (app *app) worker(ctx context.Context) {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(app.config.jobs.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		app.runQueuedJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-app.jobsQueued:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runQueuedJobs runs jobs until the queue is empty, a job is requeued after
+// an error or ctx is done. Jobs run with their own context so shutting down
+// doesn't abort them halfway.
+func (app *app) runQueuedJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := app.jobService.RunNext(context.Background())
+		if err != nil {
+			if job != nil {
+				app.logger.Error("job requeued", "id", job.ID, "error", err)
+			} else {
+				app.logger.Error("failed to run job", "error", err)
+			}
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		app.logger.Info("job finished", "id", job.ID, "status", job.Status)
+	}
+}
+
+// notifyJobQueued wakes up an idle worker, if every worker is busy the job
+// is picked up once one of them is done.
+func (app *app) notifyJobQueued() {
+	select {
+	case app.jobsQueued <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchWebhooks periodically sends the webhook deliveries of the events
+// written to the outbox. A delivery interrupted by stopWorkers isn't counted
+// as an attempt and is sent again after the retry backoff.
+func (app *app) dispatchWebhooks(ctx context.Context) {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(app.config.webhooks.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		attempts, err := app.dispatcher.Run(ctx)
+		if err != nil && ctx.Err() == nil {
+			app.logger.Error("failed to dispatch webhooks", "error", err)
+		}
+		if attempts > 0 {
+			app.logger.Info("webhook deliveries attempted", "attempts", attempts)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
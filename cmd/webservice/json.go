@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
 )
 
 type envelope map[string]any
@@ -30,8 +32,9 @@ func (api *app) sendJSON(w http.ResponseWriter, status int, data any, headers ht
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(js)))
 	w.WriteHeader(status)
-	if _, err := w.Write(js); err != nil {
+	if _, err := w.Write(js); err != nil && !errors.Is(err, http.ErrBodyNotAllowed) {
 		api.logger.Error("failed to write response body: " + err.Error())
 	}
 }
@@ -45,64 +48,78 @@ func (app *app) readJSON(w http.ResponseWriter, r *http.Request, dst any) error
 
 	err := decoder.Decode(dst)
 	if err != nil {
-		var (
-			syntaxError           *json.SyntaxError
-			unmarshalTypeError    *json.UnmarshalTypeError
-			invalidUnmarshalError *json.InvalidUnmarshalError
-			maxBytesError         *http.MaxBytesError
+		return decodeJSONError(err, "body")
+	}
+
+	err = decoder.Decode(&struct{}{})
+	if !errors.Is(err, io.EOF) {
+		return errs.Errorf(errs.EINVALID, "body must only contain a single JSON value")
+	}
+
+	return nil
+}
+
+// decodeJSONError turns a JSON decoding error into a client facing error,
+// subject names what was decoded, such as "body".
+func decodeJSONError(err error, subject string) error {
+	var (
+		syntaxError           *json.SyntaxError
+		unmarshalTypeError    *json.UnmarshalTypeError
+		invalidUnmarshalError *json.InvalidUnmarshalError
+		maxBytesError         *http.MaxBytesError
+		invalidMoneyError     *receipt.InvalidMoneyError
+	)
+
+	switch {
+	case errors.As(err, &syntaxError):
+		return errs.Errorf(
+			errs.EINVALID,
+			"%s contains badly-formed JSON (at character %d)",
+			subject,
+			syntaxError.Offset,
 		)
 
-		switch {
-		case errors.As(err, &syntaxError):
-			return errs.Errorf(
-				errs.EINVALID,
-				"body contains badly-formed JSON (at character %d)",
-				syntaxError.Offset,
-			)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errs.Errorf(errs.EINVALID, "%s contains badly-formed JSON", subject)
 
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			return errs.Errorf(errs.EINVALID, "body contains badly-formed JSON")
-
-		case errors.As(err, &unmarshalTypeError):
-			if unmarshalTypeError.Field != "" {
-				return errs.Errorf(
-					errs.EINVALID,
-					"body contains incorrect JSON type for field %q",
-					unmarshalTypeError.Field,
-				)
-			}
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field != "" {
 			return errs.Errorf(
 				errs.EINVALID,
-				"body contains incorrect JSON type (at character %d)",
-				unmarshalTypeError.Offset,
+				"%s contains incorrect JSON type for field %q",
+				subject,
+				unmarshalTypeError.Field,
 			)
+		}
+		return errs.Errorf(
+			errs.EINVALID,
+			"%s contains incorrect JSON type (at character %d)",
+			subject,
+			unmarshalTypeError.Offset,
+		)
 
-		case errors.Is(err, io.EOF):
-			return errs.Errorf(errs.EINVALID, "body must not be empty")
+	case errors.As(err, &invalidMoneyError):
+		return errs.Errorf(errs.EINVALID, "%s contains %s", subject, invalidMoneyError.Error())
 
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return errs.Errorf(
-				errs.EINVALID,
-				"body contains unknown key %s",
-				fieldName,
-			)
+	case errors.Is(err, io.EOF):
+		return errs.Errorf(errs.EINVALID, "%s must not be empty", subject)
 
-		case errors.As(err, &maxBytesError):
-			return errs.Errorf(errs.EINVALID, "body must not be larger than %d bytes", maxBytesError.Limit)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return errs.Errorf(
+			errs.EINVALID,
+			"%s contains unknown key %s",
+			subject,
+			fieldName,
+		)
 
-		case errors.As(err, &invalidUnmarshalError):
-			panic(fmt.Sprintf("readJSON: invalid unmarshal target: %v", err))
+	case errors.As(err, &maxBytesError):
+		return errs.Errorf(errs.EINVALID, "%s must not be larger than %d bytes", subject, maxBytesError.Limit)
 
-		default:
-			return errs.Errorf(errs.EINTERNAL, err.Error())
-		}
-	}
+	case errors.As(err, &invalidUnmarshalError):
+		panic(fmt.Sprintf("decodeJSONError: invalid unmarshal target: %v", err))
 
-	err = decoder.Decode(&struct{}{})
-	if !errors.Is(err, io.EOF) {
-		return errs.Errorf(errs.EINVALID, "body must only contain a single JSON value")
+	default:
+		return errs.Errorf(errs.EINTERNAL, "%s", err.Error())
 	}
-
-	return nil
 }
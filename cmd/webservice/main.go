@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"log"
 	"log/slog"
 	"os"
 	"strings"
@@ -12,7 +13,10 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/gmr458/receipt-processor/blob"
 	"github.com/gmr458/receipt-processor/env"
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
 	"github.com/gmr458/receipt-processor/sqlite"
 )
 
@@ -41,6 +45,56 @@ func main() {
 	trustedOrigins := env.Getenv[string]("CORS_TRUSTED_ORIGINS")
 	cfg.cors.trustedOrigins = strings.Fields(trustedOrigins)
 
+	cfg.rules.path = env.GetenvOrDefault("RULESET_PATH", "")
+	cfg.rules.historyDir = env.GetenvOrDefault("RULESET_HISTORY_DIR", "")
+	cfg.rules.categoriesPath = env.GetenvOrDefault("CATEGORIES_PATH", "")
+
+	duplicatePolicy, err := receipt.ParseDuplicatePolicy(env.GetenvOrDefault("DUPLICATE_POLICY", "reject"))
+	if err != nil {
+		log.Fatalf("DUPLICATE_POLICY: %s", err)
+	}
+	cfg.duplicates.policy = duplicatePolicy
+
+	validationPolicy, err := loadValidationPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.validation.policy = validationPolicy
+
+	cfg.batch.maxSize = env.GetenvOrDefault("BATCH_MAX_SIZE", 100)
+	cfg.stream.chunkSize = env.GetenvOrDefault("STREAM_CHUNK_SIZE", 500)
+	if cfg.stream.chunkSize < 1 {
+		log.Fatalf("STREAM_CHUNK_SIZE: it should be at least 1, got %d", cfg.stream.chunkSize)
+	}
+
+	cfg.csvImport.maxBytes = env.GetenvOrDefault("CSV_IMPORT_MAX_BYTES", 10_485_760)
+	cfg.csvImport.batchSize = env.GetenvOrDefault("CSV_IMPORT_BATCH_SIZE", 500)
+	if cfg.csvImport.batchSize < 1 {
+		log.Fatalf("CSV_IMPORT_BATCH_SIZE: it should be at least 1, got %d", cfg.csvImport.batchSize)
+	}
+
+	cfg.attachments.dir = env.GetenvOrDefault("ATTACHMENT_DIR", "attachments")
+	cfg.attachments.maxBytes = env.GetenvOrDefault("ATTACHMENT_MAX_BYTES", 10_485_760)
+
+	cfg.jobs.workers = env.GetenvOrDefault("JOB_WORKERS", 4)
+	if cfg.jobs.workers < 1 {
+		log.Fatalf("JOB_WORKERS: it should be at least 1, got %d", cfg.jobs.workers)
+	}
+	cfg.jobs.pollInterval = env.GetenvOrDefault("JOB_POLL_INTERVAL", 5*time.Second)
+
+	cfg.webhooks.pollInterval = env.GetenvOrDefault("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	cfg.webhooks.maxAttempts = env.GetenvOrDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	cfg.webhooks.retryBackoff = env.GetenvOrDefault("WEBHOOK_RETRY_BACKOFF", 30*time.Second)
+	cfg.webhooks.timeout = env.GetenvOrDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	cfg.webhooks.concurrency = env.GetenvOrDefault("WEBHOOK_CONCURRENCY", 4)
+	if cfg.webhooks.concurrency < 1 {
+		log.Fatalf("WEBHOOK_CONCURRENCY: it should be at least 1, got %d", cfg.webhooks.concurrency)
+	}
+
+	cfg.idempotency.ttl = env.GetenvOrDefault("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	cfg.admin.token = env.GetenvOrDefault("ADMIN_TOKEN", "")
+
 	cfg.limiter.enabled = env.GetenvOrDefault("LIMITER_ENABLED", true)
 	cfg.limiter.rps = env.GetenvOrDefault("LIMITER_RPS", 10.0)
 	cfg.limiter.burst = env.GetenvOrDefault("LIMITER_BURST", 20)
@@ -52,6 +106,19 @@ func main() {
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
+	rulesets, err := loadRulesets(cfg)
+	if err != nil {
+		logger.Error("failed to load rulesets", "error", err, "details", errs.ErrorDetails(err))
+		os.Exit(1)
+	}
+	logger.Info("rulesets loaded", "current", rulesets.Current().Version)
+
+	classifier, err := loadClassifier(cfg)
+	if err != nil {
+		logger.Error("failed to load category dictionary", "error", err, "details", errs.ErrorDetails(err))
+		os.Exit(1)
+	}
+
 	sqliteConn, err := sqlite.NewConn(cfg.db.dsn, logger, 15*time.Second)
 	if err != nil {
 		logger.Error("failed to create sqlite connection", "error", err)
@@ -71,13 +138,29 @@ func main() {
 	}
 	logger.Info("redis connection established")
 
+	blobStore, err := blob.NewFileStore(cfg.attachments.dir)
+	if err != nil {
+		logger.Error("failed to open blob store", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("blob store opened", "dir", cfg.attachments.dir)
+
 	app := newApp(
 		cfg,
 		logger,
 		sqliteConn,
 		redisClient,
+		blobStore,
+		rulesets,
+		classifier,
 	)
 
+	err = app.startWorkers()
+	if err != nil {
+		logger.Error("failed to start workers", "error", err)
+		os.Exit(1)
+	}
+
 	go func() {
 		err := app.serveDebug()
 		if err != nil {
@@ -99,3 +182,70 @@ func main() {
 		logger.Error("failed to close redis connection", "error", err)
 	}
 }
+
+// loadRulesets loads the current ruleset and the historical ones. The built-in
+// ruleset is always registered so receipts scored with it stay explainable.
+func loadRulesets(cfg config) (receipt.Rulesets, error) {
+	current := receipt.DefaultRuleset()
+	if cfg.rules.path != "" {
+		var err error
+		current, err = receipt.LoadRuleset(cfg.rules.path)
+		if err != nil {
+			return receipt.Rulesets{}, fmt.Errorf("ruleset %q: %w", cfg.rules.path, err)
+		}
+	}
+
+	history := []receipt.Ruleset{receipt.DefaultRuleset()}
+	if cfg.rules.historyDir != "" {
+		rulesets, err := receipt.LoadRulesetDir(cfg.rules.historyDir)
+		if err != nil {
+			return receipt.Rulesets{}, err
+		}
+		history = append(history, rulesets...)
+	}
+
+	return receipt.NewRulesets(current, history...)
+}
+
+// loadClassifier loads the configured category dictionary, or the built-in one.
+func loadClassifier(cfg config) (receipt.Classifier, error) {
+	if cfg.rules.categoriesPath == "" {
+		return receipt.DefaultClassifier(), nil
+	}
+
+	classifier, err := receipt.LoadClassifier(cfg.rules.categoriesPath)
+	if err != nil {
+		return receipt.Classifier{}, fmt.Errorf("category dictionary %q: %w", cfg.rules.categoriesPath, err)
+	}
+
+	return classifier, nil
+}
+
+// loadValidationPolicy reads the validation policy from the environment,
+// falling back to the default limits.
+func loadValidationPolicy() (receipt.ValidationPolicy, error) {
+	policy := receipt.DefaultValidationPolicy()
+
+	policy.RetailerMaxLength = env.GetenvOrDefault("RETAILER_MAX_LENGTH", policy.RetailerMaxLength)
+	policy.DescriptionMaxLength = env.GetenvOrDefault("DESCRIPTION_MAX_LENGTH", policy.DescriptionMaxLength)
+	policy.MaxItems = env.GetenvOrDefault("RECEIPT_MAX_ITEMS", policy.MaxItems)
+	policy.AllowFuturePurchases = env.GetenvOrDefault("ALLOW_FUTURE_PURCHASES", policy.AllowFuturePurchases)
+	policy.MaxPurchaseAgeDays = env.GetenvOrDefault("PURCHASE_MAX_AGE_DAYS", policy.MaxPurchaseAgeDays)
+	policy.MaxPage = env.GetenvOrDefault("LIST_MAX_PAGE", policy.MaxPage)
+	policy.MaxLimit = env.GetenvOrDefault("LIST_MAX_LIMIT", policy.MaxLimit)
+
+	err := policy.Validate()
+	if err != nil {
+		return receipt.ValidationPolicy{}, fmt.Errorf("validation policy: %w", err)
+	}
+
+	classes, err := receipt.ParseCharacterClasses(
+		env.GetenvOrDefault("RETAILER_CHARACTERS", "letters digits spaces punctuation symbols"),
+	)
+	if err != nil {
+		return receipt.ValidationPolicy{}, fmt.Errorf("RETAILER_CHARACTERS: %w", err)
+	}
+	policy.RetailerCharacters = classes
+
+	return policy, nil
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/gmr458/receipt-processor/errs"
 )
 
 func (api *app) recoverPanic(next http.Handler) http.Handler {
@@ -63,3 +67,33 @@ func (api *app) rateLimit(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requireAdmin only lets requests carrying the configured admin token through.
+func (api *app) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !api.isAdmin(r) {
+			api.errorResponse(w, r, &errs.Error{
+				Code:    errs.EUNAUTHORIZED,
+				Message: "A valid admin token is required",
+			})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// isAdmin reports whether the request carries the admin token as a bearer
+// token. No request is admin when no token is configured.
+func (api *app) isAdmin(r *http.Request) bool {
+	if api.config.admin.token == "" {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(api.config.admin.token)) == 1
+}
@@ -7,7 +7,7 @@ import (
 	"time"
 )
 
-func (api *app) badRequest(w http.ResponseWriter, errMsg string, details map[string]string) {
+func (api *app) badRequest(w http.ResponseWriter, errMsg string, details map[string][]string) {
 	api.sendJSON(w, http.StatusBadRequest, envelope{"error": errMsg, "details": details}, nil)
 }
 
@@ -5,9 +5,40 @@ import "net/http"
 func (app *app) setupRoutes() http.Handler {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("POST /receipts/process", app.handlerProcessReceipts)
+	mux.HandleFunc("POST /receipts/process", app.idempotent(app.handlerProcessReceipts))
+	mux.HandleFunc("POST /receipts/process:batch", app.idempotent(app.handlerProcessReceiptsBatch))
+	mux.HandleFunc("POST /receipts/process:stream", app.handlerProcessReceiptsStream)
+	mux.HandleFunc("POST /receipts/import", app.handlerImportReceipts)
+	mux.HandleFunc("POST /receipts/parse", app.handlerParseReceipt)
+	mux.HandleFunc("POST /receipts/score", app.handlerScoreReceipt)
+	mux.HandleFunc("GET /receipts/{id}", app.handlerGetReceipt)
+	mux.HandleFunc("PUT /receipts/{id}", app.handlerUpdateReceipt)
+	mux.HandleFunc("PATCH /receipts/{id}", app.handlerPatchReceipt)
+	mux.HandleFunc("DELETE /receipts/{id}", app.requireAdmin(app.handlerDeleteReceipt))
+	mux.HandleFunc("POST /receipts/{idAction}", app.handlerReceiptAction)
+	mux.HandleFunc("GET /receipts/{id}/revisions", app.handlerGetReceiptRevisions)
+	mux.HandleFunc("POST /receipts/{id}/attachments", app.handlerUploadAttachment)
+	mux.HandleFunc("GET /receipts/{id}/attachments/{aid}", app.handlerGetAttachment)
 	mux.HandleFunc("GET /receipts/{id}/points", app.handlerGetPoints)
+	mux.HandleFunc("GET /receipts/{id}/points/breakdown", app.handlerGetPointsBreakdown)
 	mux.HandleFunc("GET /receipts", app.handlerGetReceipts)
 
+	mux.HandleFunc("GET /jobs/{id}", app.handlerGetJob)
+
+	mux.HandleFunc("POST /admin/simulations", app.requireAdmin(app.handlerSimulateRuleset))
+
+	mux.HandleFunc("POST /campaigns", app.requireAdmin(app.handlerCreateCampaign))
+	mux.HandleFunc("GET /campaigns", app.handlerGetCampaigns)
+	mux.HandleFunc("GET /campaigns/{id}", app.handlerGetCampaign)
+	mux.HandleFunc("PUT /campaigns/{id}", app.requireAdmin(app.handlerUpdateCampaign))
+	mux.HandleFunc("DELETE /campaigns/{id}", app.requireAdmin(app.handlerDeleteCampaign))
+
+	mux.HandleFunc("POST /webhooks", app.requireAdmin(app.handlerCreateWebhook))
+	mux.HandleFunc("GET /webhooks", app.requireAdmin(app.handlerGetWebhooks))
+	mux.HandleFunc("GET /webhooks/{id}", app.requireAdmin(app.handlerGetWebhook))
+	mux.HandleFunc("PUT /webhooks/{id}", app.requireAdmin(app.handlerUpdateWebhook))
+	mux.HandleFunc("DELETE /webhooks/{id}", app.requireAdmin(app.handlerDeleteWebhook))
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", app.requireAdmin(app.handlerGetWebhookDeliveries))
+
 	return app.requestLogger(app.metrics(app.recoverPanic(app.corsHandler.Handler(app.rateLimit(mux)))))
 }
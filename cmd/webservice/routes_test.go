@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/cors"
+
+	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/sqlite"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	var cfg config
+	cfg.admin.token = "secret"
+
+	app := &app{
+		config:      cfg,
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		corsHandler: cors.New(cors.Options{}),
+	}
+	routes := app.setupRoutes()
+
+	id := "9f3c6d0e-6f4a-4b6e-8b0a-2f1d7c5e4a10"
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+	}{
+		{"delete receipt without token", http.MethodDelete, "/receipts/" + id, ""},
+		{"delete receipt with wrong token", http.MethodDelete, "/receipts/" + id, "Bearer wrong"},
+		{"restore receipt without token", http.MethodPost, "/receipts/" + id + ":restore", ""},
+		{"restore receipt with wrong token", http.MethodPost, "/receipts/" + id + ":restore", "Bearer wrong"},
+		{"purge receipt without token", http.MethodPost, "/receipts/" + id + ":purge", ""},
+		{"create campaign without token", http.MethodPost, "/campaigns", ""},
+		{"update campaign without token", http.MethodPut, "/campaigns/" + id, ""},
+		{"delete campaign without token", http.MethodDelete, "/campaigns/" + id, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			if test.authorization != "" {
+				r.Header.Set("Authorization", test.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			routes.ServeHTTP(w, r)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("expected status %d. got %d, %s", http.StatusUnauthorized, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestScoreRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	conn, err := sqlite.NewConn(":memory:", logger, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	rulesets, err := receipt.NewRulesets(receipt.DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repository := sqlite.NewRepository(conn)
+	app := &app{
+		logger: logger,
+		receiptService: receipt.NewService(
+			repository.Receipt,
+			repository.Campaign,
+			nil,
+			rulesets,
+			receipt.DefaultClassifier(),
+			receipt.DuplicateReject,
+			receipt.DefaultValidationPolicy(),
+		),
+		corsHandler: cors.New(cors.Options{}),
+	}
+	routes := app.setupRoutes()
+
+	valid := `{
+		"retailer": "Target",
+		"purchaseDate": "2022-01-01",
+		"purchaseTime": "13:01",
+		"total": "1.25",
+		"items": [{"shortDescription": "Pepsi - 12-oz", "price": "1.25"}]
+	}`
+	tests := []struct {
+		name   string
+		query  string
+		body   string
+		status int
+	}{
+		{"valid receipt", "", valid, http.StatusOK},
+		{"valid receipt with a ruleset version", "?rulesetVersion=" + rulesets.Current().Version, valid, http.StatusOK},
+		{"unknown ruleset version", "?rulesetVersion=unknown", valid, http.StatusNotFound},
+		{"invalid receipt", "", `{"retailer": ""}`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/receipts/score"+test.query, strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+
+			routes.ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("expected status %d. got %d, %s", test.status, w.Code, w.Body.String())
+			}
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var breakdown receipt.PointsBreakdown
+			err := json.Unmarshal(w.Body.Bytes(), &breakdown)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if breakdown.Points == 0 || breakdown.RulesetVersion != rulesets.Current().Version {
+				t.Errorf("expected points with ruleset %s. got %+v", rulesets.Current().Version, breakdown)
+			}
+		})
+	}
+
+	var count int
+	err = conn.DB.QueryRow("SELECT count(*) FROM receipt").Scan(&count)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no stored receipts. got %d", count)
+	}
+}
@@ -93,6 +93,7 @@ func (app *app) setupShutdown(server *http.Server, serverName string) chan error
 
 		app.logger.Info("completing background tasks", "addr", server.Addr)
 
+		app.stopWorkers()
 		app.wg.Wait()
 		shutdownError <- nil
 	}()
@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"slices"
 	"strconv"
+
+	"github.com/gmr458/receipt-processor/errs"
 )
 
 // getURLValueStr returns the URL parameter value if it's in safeValues,
@@ -40,3 +42,26 @@ func getURLValuePositiveInt(
 
 	return value
 }
+
+// getURLValueBool returns the URL parameter as a boolean, false if it's
+// missing. Unlike the other getters an invalid value is an error, since
+// ignoring a flag changes what the request does.
+func getURLValueBool(values url.Values, key string) (bool, error) {
+	s := values.Get(key)
+	if s == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid query parameter",
+			Details: map[string][]string{
+				key: {key + " must be true or false"},
+			},
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,386 @@
+// Package csvimport imports receipts from CSV files.
+//
+// The first row is a header naming the columns, in any order. Every other row
+// is one item; rows sharing a receipt_key are grouped into one receipt and
+// must agree on the receipt columns.
+//
+//	receipt_key       required, groups the rows of a receipt
+//	retailer          required
+//	purchase_date     required, YYYY-MM-DD
+//	purchase_time     required, hh:mm
+//	timezone          optional, IANA timezone name or UTC offset
+//	total             required, decimal with up to two decimals
+//	item_description  required
+//	item_price        required, decimal with up to two decimals
+//	item_quantity     optional, whole number of units, 1 if empty
+//	item_unit_price   optional, required if item_quantity is over 1
+//	item_category     optional, classified from item_description if empty
+//
+// Row numbers in reports count the header as row 1, matching what a
+// spreadsheet shows.
+package csvimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+const (
+	ColumnReceiptKey      = "receipt_key"
+	ColumnRetailer        = "retailer"
+	ColumnPurchaseDate    = "purchase_date"
+	ColumnPurchaseTime    = "purchase_time"
+	ColumnTimezone        = "timezone"
+	ColumnTotal           = "total"
+	ColumnItemDescription = "item_description"
+	ColumnItemPrice       = "item_price"
+	ColumnItemQuantity    = "item_quantity"
+	ColumnItemUnitPrice   = "item_unit_price"
+	ColumnItemCategory    = "item_category"
+)
+
+var requiredColumns = []string{
+	ColumnReceiptKey,
+	ColumnRetailer,
+	ColumnPurchaseDate,
+	ColumnPurchaseTime,
+	ColumnTotal,
+	ColumnItemDescription,
+	ColumnItemPrice,
+}
+
+var optionalColumns = []string{
+	ColumnTimezone,
+	ColumnItemQuantity,
+	ColumnItemUnitPrice,
+	ColumnItemCategory,
+}
+
+// receiptColumns must hold the same value on every row of a receipt.
+var receiptColumns = []string{
+	ColumnRetailer,
+	ColumnPurchaseDate,
+	ColumnPurchaseTime,
+	ColumnTimezone,
+	ColumnTotal,
+}
+
+// Processor stores a batch of receipts, receipt.Service implements it.
+type Processor interface {
+	ProcessBatch(ctx context.Context, dtos []receipt.ReceiptDTO) ([]receipt.BatchResult, error)
+}
+
+// Receipt is a receipt read from a CSV file, Rows are the rows it was read
+// from.
+type Receipt struct {
+	Key  string
+	Rows []int
+	DTO  receipt.ReceiptDTO
+
+	// errors are the problems found while reading the rows, a receipt with
+	// errors is never processed.
+	errors []RowError
+}
+
+// RowError is a problem with one field of a receipt, Rows are the rows it
+// concerns.
+type RowError struct {
+	Rows       []int  `json:"rows"`
+	ReceiptKey string `json:"receiptKey"`
+	Field      string `json:"field"`
+	Message    string `json:"message"`
+}
+
+// Imported is a receipt that was stored.
+type Imported struct {
+	ReceiptKey string `json:"receiptKey"`
+	ID         string `json:"id"`
+	Points     int    `json:"points"`
+}
+
+type Report struct {
+	Receipts int        `json:"receipts"`
+	Created  int        `json:"created"`
+	Failed   int        `json:"failed"`
+	Imported []Imported `json:"imported"`
+	Errors   []RowError `json:"errors"`
+}
+
+// Import reads the CSV in r and processes its receipts in batches of at most
+// batchSize, each batch in its own transaction. Invalid receipts are reported
+// and skipped, they don't prevent the others from being imported.
+func Import(ctx context.Context, p Processor, r io.Reader, batchSize int) (Report, error) {
+	receipts, rowErrors, err := Read(r)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Receipts: len(receipts),
+		Imported: []Imported{},
+		Errors:   rowErrors,
+	}
+
+	valid := make([]Receipt, 0, len(receipts))
+	for _, rec := range receipts {
+		if len(rec.errors) != 0 {
+			report.Failed++
+			report.Errors = append(report.Errors, rec.errors...)
+			continue
+		}
+		valid = append(valid, rec)
+	}
+
+	for batch := range slices.Chunk(valid, max(1, batchSize)) {
+		dtos := make([]receipt.ReceiptDTO, len(batch))
+		for i, rec := range batch {
+			dtos[i] = rec.DTO
+		}
+
+		results, err := p.ProcessBatch(ctx, dtos)
+		if err != nil {
+			return Report{}, err
+		}
+
+		for _, result := range results {
+			rec := batch[result.Index]
+			if result.Errors != nil {
+				report.Failed++
+				report.Errors = append(report.Errors, fieldErrors(rec, result.Errors)...)
+				continue
+			}
+
+			report.Created++
+			report.Imported = append(report.Imported, Imported{
+				ReceiptKey: rec.Key,
+				ID:         result.ID,
+				Points:     *result.Points,
+			})
+		}
+	}
+
+	slices.SortStableFunc(report.Errors, func(a, b RowError) int {
+		return a.Rows[0] - b.Rows[0]
+	})
+
+	return report, nil
+}
+
+// Read parses the CSV in r into receipts, in the order their first row
+// appears. Rows that can't be parsed as CSV are returned as errors on their
+// own, they belong to no receipt.
+func Read(r io.Reader) ([]Receipt, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errs.Errorf(errs.EINVALID, "csv file must not be empty")
+		}
+		return nil, nil, errs.Errorf(errs.EINVALID, "invalid csv header: %s", err.Error())
+	}
+
+	columns, err := parseHeader(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receipts := []Receipt{}
+	byKey := map[string]int{}
+	rowErrors := []RowError{}
+	firstRows := map[string]map[string]string{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			rowErrors = append(rowErrors, RowError{
+				Rows:    []int{parseError.StartLine},
+				Field:   "row",
+				Message: parseError.Err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := make(map[string]string, len(columns))
+		for name, i := range columns {
+			row[name] = strings.TrimSpace(record[i])
+		}
+
+		key := row[ColumnReceiptKey]
+		if key == "" {
+			rowErrors = append(rowErrors, RowError{
+				Rows:    []int{line},
+				Field:   ColumnReceiptKey,
+				Message: "receipt_key cannot be empty",
+			})
+			continue
+		}
+
+		i, exists := byKey[key]
+		if !exists {
+			i = len(receipts)
+			byKey[key] = i
+			firstRows[key] = row
+			receipts = append(receipts, newReceipt(key, line, row))
+		}
+
+		addItem(&receipts[i], line, row, firstRows[key])
+	}
+
+	return receipts, rowErrors, nil
+}
+
+func parseHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	details := map[string][]string{}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(requiredColumns, name) && !slices.Contains(optionalColumns, name) {
+			details[name] = append(details[name], "unknown column")
+			continue
+		}
+		if _, exists := columns[name]; exists {
+			details[name] = append(details[name], "duplicated column")
+			continue
+		}
+		columns[name] = i
+	}
+
+	for _, name := range requiredColumns {
+		if _, exists := columns[name]; !exists {
+			details[name] = append(details[name], "missing column")
+		}
+	}
+
+	if len(details) != 0 {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid csv header",
+			Details: details,
+		}
+	}
+
+	return columns, nil
+}
+
+// newReceipt starts a receipt from the receipt columns of its first row.
+func newReceipt(key string, line int, row map[string]string) Receipt {
+	rec := Receipt{
+		Key: key,
+		DTO: receipt.ReceiptDTO{
+			Retailer:     row[ColumnRetailer],
+			PurchaseDate: row[ColumnPurchaseDate],
+			PurchaseTime: row[ColumnPurchaseTime],
+			Timezone:     row[ColumnTimezone],
+			Items:        []receipt.ItemDTO{},
+		},
+	}
+
+	total, err := receipt.ParseMoney(row[ColumnTotal])
+	if err != nil {
+		rec.addError(ColumnTotal, err.Error(), line)
+	}
+	rec.DTO.Total = total
+
+	return rec
+}
+
+// addItem adds the item of row to the receipt after checking the row agrees
+// with the first row of the receipt.
+func addItem(rec *Receipt, line int, row, first map[string]string) {
+	rec.Rows = append(rec.Rows, line)
+
+	for _, name := range receiptColumns {
+		if row[name] != first[name] {
+			rec.addError(name, fmt.Sprintf("%s differs from row %d", name, rec.Rows[0]), line)
+		}
+	}
+
+	price, err := receipt.ParseMoney(row[ColumnItemPrice])
+	if err != nil {
+		rec.addError(ColumnItemPrice, err.Error(), line)
+	}
+
+	item := receipt.ItemDTO{
+		ShortDescription: row[ColumnItemDescription],
+		Price:            price,
+		Category:         receipt.Category(row[ColumnItemCategory]),
+	}
+
+	if s := row[ColumnItemQuantity]; s != "" {
+		item.Quantity, err = strconv.Atoi(s)
+		if err != nil {
+			rec.addError(ColumnItemQuantity, fmt.Sprintf("invalid quantity %q, it should be a whole number", s), line)
+		}
+	}
+
+	if s := row[ColumnItemUnitPrice]; s != "" {
+		item.UnitPrice, err = receipt.ParseMoney(s)
+		if err != nil {
+			rec.addError(ColumnItemUnitPrice, err.Error(), line)
+		}
+	}
+
+	rec.DTO.Items = append(rec.DTO.Items, item)
+}
+
+func (rec *Receipt) addError(field, message string, rows ...int) {
+	rec.errors = append(rec.errors, RowError{
+		Rows:       rows,
+		ReceiptKey: rec.Key,
+		Field:      field,
+		Message:    message,
+	})
+}
+
+// fieldErrors reports the validation errors of a receipt, in field order.
+// Errors of an item, like items[2].price, concern the item's row, the other
+// errors all the rows of the receipt.
+func fieldErrors(rec Receipt, details map[string][]string) []RowError {
+	fields := make([]string, 0, len(details))
+	for field := range details {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	rowErrors := make([]RowError, 0, len(fields))
+	for _, field := range fields {
+		rows := rec.Rows
+		var item int
+		_, err := fmt.Sscanf(field, "items[%d]", &item)
+		if err == nil && item >= 0 && item < len(rec.Rows) {
+			rows = rec.Rows[item : item+1]
+		}
+
+		for _, message := range details[field] {
+			rowErrors = append(rowErrors, RowError{
+				Rows:       rows,
+				ReceiptKey: rec.Key,
+				Field:      field,
+				Message:    message,
+			})
+		}
+	}
+
+	return rowErrors
+}
@@ -0,0 +1,186 @@
+package csvimport
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+const header = "receipt_key,retailer,purchase_date,purchase_time,total,item_description,item_price\n"
+
+type fakeProcessor struct {
+	batches [][]receipt.ReceiptDTO
+}
+
+func (p *fakeProcessor) ProcessBatch(ctx context.Context, dtos []receipt.ReceiptDTO) ([]receipt.BatchResult, error) {
+	p.batches = append(p.batches, dtos)
+
+	results := make([]receipt.BatchResult, len(dtos))
+	for i, dto := range dtos {
+		results[i].Index = i
+		isValid, errors := dto.IsValid(receipt.DefaultValidationPolicy())
+		if !isValid {
+			results[i].Errors = errors
+			continue
+		}
+		points := 10
+		results[i].ID = "id-" + dto.Retailer
+		results[i].Points = &points
+	}
+
+	return results, nil
+}
+
+func TestRead(t *testing.T) {
+	input := header +
+		"a,Target,2022-01-01,13:01,3.50,Pepsi,1.25\n" +
+		"b,Walmart,2022-01-02,10:00,1.00,Gum,1.00\n" +
+		"a,Target,2022-01-01,13:01,3.50,Doritos,2.25\n"
+
+	receipts, rowErrors, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rowErrors) != 0 {
+		t.Fatalf("expected no row errors. got %+v", rowErrors)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("expected 2 receipts. got %d", len(receipts))
+	}
+
+	a := receipts[0]
+	if a.Key != "a" || len(a.DTO.Items) != 2 {
+		t.Errorf("expected receipt a with 2 items. got %+v", a)
+	}
+	if a.Rows[0] != 2 || a.Rows[1] != 4 {
+		t.Errorf("expected rows [2 4]. got %v", a.Rows)
+	}
+	if a.DTO.Total.String() != "3.50" || a.DTO.Items[1].Price.String() != "2.25" {
+		t.Errorf("unexpected amounts: %+v", a.DTO)
+	}
+}
+
+func TestReadQuantity(t *testing.T) {
+	input := "receipt_key,retailer,purchase_date,purchase_time,total,item_description,item_price,item_quantity,item_unit_price\n" +
+		"a,Target,2022-01-01,13:01,7.75,Gatorade,6.75,3,2.25\n" +
+		"a,Target,2022-01-01,13:01,7.75,Gum,1.00,,\n" +
+		"b,Target,2022-01-01,13:01,6.75,Gatorade,6.75,three,2.25\n"
+
+	receipts, _, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items := receipts[0].DTO.Items
+	if items[0].Quantity != 3 || items[0].UnitPrice.String() != "2.25" {
+		t.Errorf("expected 3 units of 2.25. got %+v", items[0])
+	}
+	if items[1].Quantity != 0 || items[1].UnitPrice != 0 {
+		t.Errorf("expected no quantity. got %+v", items[1])
+	}
+
+	if len(receipts[1].errors) != 1 || receipts[1].errors[0].Field != ColumnItemQuantity {
+		t.Errorf("expected an item_quantity error. got %+v", receipts[1].errors)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	_, _, err := Read(strings.NewReader("receipt_key,retailer,color\n"))
+	if errs.ErrorCode(err) != errs.EINVALID {
+		t.Fatalf("expected an invalid error. got %v", err)
+	}
+
+	details := errs.ErrorDetails(err)
+	if !slices.Equal(details["color"], []string{"unknown column"}) {
+		t.Errorf("expected color to be an unknown column. got %q", details["color"])
+	}
+	if !slices.Equal(details["total"], []string{"missing column"}) {
+		t.Errorf("expected total to be a missing column. got %q", details["total"])
+	}
+}
+
+func TestImport(t *testing.T) {
+	input := header +
+		"a,Target,2022-01-01,13:01,1.25,Pepsi,1.25\n" +
+		"b,Walmart,2022-01-02,10:00,5.00,Gum,1.00\n" +
+		"c,Costco,2022-01-02,10:00,1.00,Gum,1.x\n" +
+		"d,Aldi,2022-01-02,10:00,1.00,Gum\n" +
+		"c,Costco,2022-01-03,10:00,1.00,Mints,1.00\n" +
+		"e,Kroger,2022-01-02,10:00,2.00,Milk,2.00\n"
+
+	processor := &fakeProcessor{}
+	report, err := Import(context.Background(), processor, strings.NewReader(input), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Receipts != 4 || report.Created != 2 || report.Failed != 2 {
+		t.Errorf("expected 4 receipts, 2 created and 2 failed. got %+v", report)
+	}
+	if len(processor.batches) != 3 {
+		t.Errorf("expected 3 batches of one receipt. got %d", len(processor.batches))
+	}
+
+	expected := []struct {
+		row   int
+		key   string
+		field string
+	}{
+		{3, "b", "total"},
+		{4, "c", "item_price"},
+		{5, "", "row"},
+		{6, "c", "purchase_date"},
+	}
+	if len(report.Errors) != len(expected) {
+		t.Fatalf("expected %d errors. got %+v", len(expected), report.Errors)
+	}
+	for i, e := range expected {
+		got := report.Errors[i]
+		if got.Rows[0] != e.row || got.ReceiptKey != e.key || got.Field != e.field {
+			t.Errorf("expected error on row %d, key %q, field %q. got %+v", e.row, e.key, e.field, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = report.WriteErrors(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "rows,receipt_key,field,error" || len(lines) != 5 {
+		t.Errorf("unexpected error report:\n%s", buf.String())
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	rec := Receipt{Key: "a", Rows: []int{2, 3}}
+	details := map[string][]string{
+		"items[1].price": {"price must be greater than 0.00"},
+		"total":          {"the total must be greater than 0.00", "total field should be equal to the sum of all items price"},
+	}
+
+	expected := []struct {
+		rows  []int
+		field string
+	}{
+		{[]int{3}, "items[1].price"},
+		{[]int{2, 3}, "total"},
+		{[]int{2, 3}, "total"},
+	}
+
+	rowErrors := fieldErrors(rec, details)
+	if len(rowErrors) != len(expected) {
+		t.Fatalf("expected %d errors. got %+v", len(expected), rowErrors)
+	}
+	for i, e := range expected {
+		got := rowErrors[i]
+		if !slices.Equal(got.Rows, e.rows) || got.Field != e.field {
+			t.Errorf("expected error on rows %v, field %q. got %+v", e.rows, e.field, got)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package csvimport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteErrors writes the errors of the report as CSV, one line per error with
+// the rows it concerns separated by spaces.
+func (report Report) WriteErrors(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"rows", ColumnReceiptKey, "field", "error"})
+	if err != nil {
+		return err
+	}
+
+	for _, rowError := range report.Errors {
+		rows := make([]string, len(rowError.Rows))
+		for i, row := range rowError.Rows {
+			rows[i] = strconv.Itoa(row)
+		}
+
+		err = writer.Write([]string{
+			strings.Join(rows, " "),
+			rowError.ReceiptKey,
+			rowError.Field,
+			rowError.Message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
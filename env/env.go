@@ -4,9 +4,10 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 )
 
-func Getenv[T int | float64 | string | bool](key string) T {
+func Getenv[T int | float64 | string | bool | time.Duration](key string) T {
 	value := os.Getenv(key)
 	if value == "" {
 		log.Fatalf("%s environment variable is empty", key)
@@ -37,12 +38,19 @@ func Getenv[T int | float64 | string | bool](key string) T {
 
 	case string:
 		result = any(value).(T)
+
+	case time.Duration:
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("%s: invalid duration value %q", key, value)
+		}
+		result = any(v).(T)
 	}
 
 	return result
 }
 
-func GetenvOrDefault[T int | float64 | string | bool](key string, defaultVal T) T {
+func GetenvOrDefault[T int | float64 | string | bool | time.Duration](key string, defaultVal T) T {
 	value := os.Getenv(key)
 	if value == "" {
 		return defaultVal
@@ -73,6 +81,13 @@ func GetenvOrDefault[T int | float64 | string | bool](key string, defaultVal T)
 		}
 		return any(b).(T)
 
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("%s environment variable must be a duration, such as 24h", key)
+		}
+		return any(d).(T)
+
 	default:
 		return defaultVal
 	}
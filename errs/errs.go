@@ -18,10 +18,12 @@ const (
 	EUNAUTHORIZED         = "unauthorized"
 )
 
+// Error is an error reported to clients. Details maps each field, or field
+// path like items[2].price, to its error messages.
 type Error struct {
 	Code    string
 	Message string
-	Details map[string]string
+	Details map[string][]string
 }
 
 func (e *Error) Error() string {
@@ -52,7 +54,7 @@ func ErrorMessage(err error) string {
 	return "internal error"
 }
 
-func ErrorDetails(err error) map[string]string {
+func ErrorDetails(err error) map[string][]string {
 	var e *Error
 
 	if nil == err {
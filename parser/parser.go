@@ -0,0 +1,446 @@
+// Package parser turns the OCR'd text of a paper receipt into a
+// receipt.ReceiptDTO.
+//
+// It expects the layout most receipts share:
+//
+//	M&M CORNER MARKET           retailer, the first line with letters
+//	123 MAIN ST                 lines without a price are ignored
+//	03/20/2022 14:33            date and time, on one line or two
+//	GATORADE ........ 2.25      items, a description then a price
+//	DORITOS           2.25 N    a trailing tax flag letter is ignored
+//	3 X PEPSI @ 1.25  3.75      several units, "PEPSI 3 @ 1.25" also works
+//	SUBTOTAL          4.50      subtotal and payment lines are skipped
+//	TAX               0.36      tax, discount, tip and fee lines are read
+//	                            as adjustments
+//	TOTAL             4.86      the total, items end here
+//
+// Dates are read as YYYY-MM-DD, or MM/DD/YYYY unless the first number can't
+// be a month. Times are read as hh:mm with an optional AM/PM.
+//
+// OCR is noisy, so every field is reported with a confidence between 0 and 1
+// and the line it was read from. The DTO isn't validated, a field that
+// couldn't be read is left empty.
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+const (
+	// Exact is the confidence of a value read in an unambiguous format and
+	// cross-checked when possible.
+	Exact = 1.0
+
+	// Likely is the confidence of a value read in the expected place and
+	// format.
+	Likely = 0.8
+
+	// Guess is the confidence of a value read from an ambiguous or noisy
+	// line, or derived from other fields.
+	Guess = 0.5
+
+	// Missing is the confidence of a field that couldn't be read.
+	Missing = 0.0
+)
+
+// FieldReport tells how a field was read. Line counts from 1 and is 0 if the
+// field wasn't read from a single line.
+type FieldReport struct {
+	Confidence float64 `json:"confidence"`
+	Line       int     `json:"line,omitempty"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// Result is a parsed receipt. Fields is keyed by the DTO's JSON field names,
+// items are reported both as a whole under "items" and one by one under
+// "items[i]", adjustments one by one under "adjustments[i]".
+type Result struct {
+	Receipt receipt.ReceiptDTO     `json:"receipt"`
+	Fields  map[string]FieldReport `json:"fields"`
+}
+
+var (
+	isoDateRegex   = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	slashDateRegex = regexp.MustCompile(`\b(\d{1,2})[/.-](\d{1,2})[/.-](\d{4}|\d{2})\b`)
+	timeRegex      = regexp.MustCompile(`(?i)\b(\d{1,2}):(\d{2})(?::\d{2})?(?:\s*([AP])\.?M\b\.?)?`)
+
+	// priceLineRegex matches a description followed by dots or spaces, a
+	// price and an optional tax flag letter.
+	priceLineRegex = regexp.MustCompile(`^(.*?[[:alpha:]].*?)[\s.]+\$?(\d+[.,]\d{2})(?:\s+[A-Z])?$`)
+
+	// leadingQuantityRegex matches "3 X GATORADE", optionally followed by
+	// "@" and the unit price, trailingQuantityRegex matches "GATORADE 3 @".
+	leadingQuantityRegex  = regexp.MustCompile(`^(\d+)\s*[xX*]\s*(.*?[[:alpha:]].*?)(?:\s*(@)\s*(?:\$?(\d+[.,]\d{2}))?)?$`)
+	trailingQuantityRegex = regexp.MustCompile(`^(.*?[[:alpha:]].*?)\s+(\d+)\s*(@)\s*(?:\$?(\d+[.,]\d{2}))?$`)
+
+	totalRegex = regexp.MustCompile(`(?i)^(?:grand\s+)?total\b|^amount\s+due\b|^balance\s+due\b`)
+	skipRegex  = regexp.MustCompile(`(?i)^(?:sub\s*-?\s*total|change|cash|card|visa|mastercard|amex|debit|credit|tender|payment)\b`)
+
+	// adjustmentRegexes match the descriptions of adjustment lines, checked
+	// in order.
+	adjustmentRegexes = []struct {
+		kind  receipt.AdjustmentKind
+		regex *regexp.Regexp
+	}{
+		{receipt.AdjustmentTax, regexp.MustCompile(`(?i)^(?:sales\s+)?tax\b|^(?:vat|gst|hst)\b`)},
+		{receipt.AdjustmentDiscount, regexp.MustCompile(`(?i)^(?:discount|coupon|savings|promo)\b`)},
+		{receipt.AdjustmentTip, regexp.MustCompile(`(?i)^(?:tip|gratuity)\b`)},
+		{receipt.AdjustmentFee, regexp.MustCompile(`(?i)\bfee\b|^(?:bag|deposit|service\s+charge)\b`)},
+	}
+
+	// noiseRegex matches characters seldom printed on receipts, usually
+	// misread by OCR.
+	noiseRegex = regexp.MustCompile(`[^[:alnum:]\s&'./%#()+-]`)
+)
+
+// Parse reads the receipt in text.
+func Parse(text string) Result {
+	p := parse{
+		result: Result{
+			Receipt: receipt.ReceiptDTO{Items: []receipt.ItemDTO{}},
+			Fields:  map[string]FieldReport{},
+		},
+		dateLine: -1,
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if p.readLine(i+1, line) {
+			break
+		}
+	}
+
+	p.finish()
+
+	return p.result
+}
+
+// parse holds the state of a Parse call.
+type parse struct {
+	result           Result
+	dateLine         int
+	itemsTotal       receipt.Money
+	adjustmentsTotal receipt.Money
+	totalFound       bool
+}
+
+// readLine reads a single trimmed line and reports whether it is the total,
+// nothing after it is read.
+func (p *parse) readLine(n int, line string) bool {
+	if _, ok := p.result.Fields["retailer"]; !ok {
+		if p.readRetailer(n, line) {
+			return false
+		}
+	}
+
+	if _, ok := p.result.Fields["purchaseDate"]; !ok && p.readDate(n, line) {
+		p.dateLine = n
+	}
+	if _, ok := p.result.Fields["purchaseTime"]; !ok {
+		p.readTime(n, line)
+	}
+
+	matches := priceLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return false
+	}
+
+	description := strings.TrimRight(matches[1], " .:$")
+	price, priceReport := readPrice(n, matches[2])
+
+	switch {
+	case totalRegex.MatchString(description):
+		p.result.Receipt.Total = price
+		p.result.Fields["total"] = priceReport
+		p.totalFound = true
+		return true
+
+	case skipRegex.MatchString(description):
+		return false
+	}
+
+	for _, adjustment := range adjustmentRegexes {
+		if adjustment.regex.MatchString(description) {
+			p.readAdjustment(adjustment.kind, description, price, priceReport)
+			return false
+		}
+	}
+
+	p.readItem(n, description, price, priceReport)
+
+	return false
+}
+
+// readItem adds an item line. Lines of several units are read as such, the
+// price at the end of the line is the line total unless the line ends with
+// "@ price", then it is the unit price.
+func (p *parse) readItem(n int, description string, price receipt.Money, report FieldReport) {
+	item := receipt.ItemDTO{ShortDescription: description, Price: price}
+
+	m := leadingQuantityRegex.FindStringSubmatch(description)
+	if m == nil {
+		m = trailingQuantityRegex.FindStringSubmatch(description)
+		if m != nil {
+			m[1], m[2] = m[2], m[1]
+		}
+	}
+	if m != nil {
+		quantity, _ := strconv.Atoi(m[1])
+		item.ShortDescription = m[2]
+		item, report = readUnits(n, item, quantity, m[3] != "", m[4], report)
+	}
+
+	if noiseRegex.MatchString(item.ShortDescription) {
+		report = lower(report, Guess, "description contains unusual characters")
+	}
+
+	p.result.Fields[fmt.Sprintf("items[%d]", len(p.result.Receipt.Items))] = report
+	p.result.Receipt.Items = append(p.result.Receipt.Items, item)
+	p.itemsTotal += item.Price
+}
+
+// readUnits sets the quantity and unit price of an item. at tells whether the
+// line had an "@", unitPrice is the amount following it if any. Without a
+// unit price the price is divided by the quantity, if there's no whole
+// number of cents per unit the item is read as a single unit.
+func readUnits(
+	n int,
+	item receipt.ItemDTO,
+	quantity int,
+	at bool,
+	unitPrice string,
+	report FieldReport,
+) (receipt.ItemDTO, FieldReport) {
+	if at && unitPrice == "" {
+		item.UnitPrice = item.Price
+		item.Price = item.UnitPrice * receipt.Money(max(quantity, 1))
+		report = lower(report, report.Confidence, "line total computed from the unit price")
+	}
+
+	if quantity <= 1 {
+		item.UnitPrice = 0
+		return item, report
+	}
+	item.Quantity = quantity
+
+	switch {
+	case unitPrice != "":
+		var unitReport FieldReport
+		item.UnitPrice, unitReport = readPrice(n, unitPrice)
+		if unitReport.Confidence < report.Confidence {
+			report = lower(report, unitReport.Confidence, unitReport.Note)
+		}
+		if item.UnitPrice*receipt.Money(quantity) != item.Price {
+			report = lower(report, Guess, "price is not quantity times unit price")
+		}
+
+	case item.UnitPrice == 0 && item.Price%receipt.Money(quantity) != 0:
+		item.Quantity = 0
+		report = lower(report, Guess, "quantity doesn't divide the price, read as a single unit")
+
+	case item.UnitPrice == 0:
+		item.UnitPrice = item.Price / receipt.Money(quantity)
+	}
+
+	return item, report
+}
+
+// readAdjustment adds an adjustment line, lines with a zero amount such as a
+// tax of 0.00 are left out.
+func (p *parse) readAdjustment(kind receipt.AdjustmentKind, description string, amount receipt.Money, report FieldReport) {
+	if amount == 0 {
+		return
+	}
+
+	adjustment := receipt.AdjustmentDTO{
+		Kind:        kind,
+		Description: description,
+		Amount:      amount,
+	}
+
+	p.result.Fields[fmt.Sprintf("adjustments[%d]", len(p.result.Receipt.Adjustments))] = report
+	p.result.Receipt.Adjustments = append(p.result.Receipt.Adjustments, adjustment)
+	p.adjustmentsTotal += receipt.Adjustment(adjustment).Signed()
+}
+
+// readRetailer takes the first line with letters as the retailer, a line
+// with digits is more likely an address or a receipt number.
+func (p *parse) readRetailer(n int, line string) bool {
+	if !strings.ContainsFunc(line, unicode.IsLetter) || priceLineRegex.MatchString(line) {
+		return false
+	}
+
+	report := FieldReport{Confidence: Likely, Line: n}
+	if strings.ContainsFunc(line, unicode.IsDigit) {
+		report = lower(report, Guess, "the header contains digits")
+	}
+
+	p.result.Receipt.Retailer = line
+	p.result.Fields["retailer"] = report
+
+	return true
+}
+
+func (p *parse) readDate(n int, line string) bool {
+	if m := isoDateRegex.FindStringSubmatch(line); m != nil {
+		date, ok := makeDate(m[1], m[2], m[3])
+		if ok {
+			p.result.Receipt.PurchaseDate = date
+			p.result.Fields["purchaseDate"] = FieldReport{Confidence: Exact, Line: n}
+			return true
+		}
+	}
+
+	m := slashDateRegex.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+
+	year := m[3]
+	if len(year) == 2 {
+		year = "20" + year
+	}
+
+	report := FieldReport{Confidence: Likely, Line: n}
+	date, ok := makeDate(year, m[1], m[2])
+	if !ok {
+		date, ok = makeDate(year, m[2], m[1])
+		report = lower(report, Guess, "read as DD/MM/YYYY")
+	}
+	if !ok {
+		return false
+	}
+
+	p.result.Receipt.PurchaseDate = date
+	p.result.Fields["purchaseDate"] = report
+
+	return true
+}
+
+func (p *parse) readTime(n int, line string) {
+	m := timeRegex.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	switch strings.ToUpper(m[3]) {
+	case "A":
+		if hour == 12 {
+			hour = 0
+		}
+	case "P":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return
+	}
+
+	report := FieldReport{Confidence: Exact, Line: n}
+	if n != p.dateLine {
+		report = lower(report, Likely, "not on the date line")
+	}
+
+	p.result.Receipt.PurchaseTime = fmt.Sprintf("%02d:%02d", hour, minute)
+	p.result.Fields["purchaseTime"] = report
+}
+
+// finish reports the fields that weren't read and cross-checks the total with
+// the items and adjustments.
+func (p *parse) finish() {
+	for _, field := range []string{"retailer", "purchaseDate", "purchaseTime"} {
+		if _, ok := p.result.Fields[field]; !ok {
+			p.result.Fields[field] = FieldReport{Confidence: Missing, Note: "not found"}
+		}
+	}
+
+	items := FieldReport{Confidence: Missing, Note: "no item lines found"}
+	if len(p.result.Receipt.Items) > 0 {
+		items = FieldReport{Confidence: Exact}
+		for i := range p.result.Receipt.Items {
+			items.Confidence = min(items.Confidence, p.result.Fields[fmt.Sprintf("items[%d]", i)].Confidence)
+		}
+	}
+	p.result.Fields["items"] = items
+
+	expectedTotal := p.itemsTotal + p.adjustmentsTotal
+
+	switch {
+	case !p.totalFound && len(p.result.Receipt.Items) == 0:
+		p.result.Fields["total"] = FieldReport{Confidence: Missing, Note: "not found"}
+
+	case !p.totalFound:
+		p.result.Receipt.Total = expectedTotal
+		p.result.Fields["total"] = FieldReport{Confidence: Guess, Note: "no total line, the items and adjustments add up to it"}
+
+	case p.result.Receipt.Total == expectedTotal:
+		report := p.result.Fields["total"]
+		if report.Note == "" {
+			report.Confidence = Exact
+		}
+		p.result.Fields["total"] = report
+
+	default:
+		p.result.Fields["total"] = lower(
+			p.result.Fields["total"],
+			Guess,
+			fmt.Sprintf("the items and adjustments add up to %s", expectedTotal),
+		)
+	}
+}
+
+// readPrice reads a price matched by priceLineRegex, OCR often reads the
+// decimal point as a comma.
+func readPrice(n int, s string) (receipt.Money, FieldReport) {
+	report := FieldReport{Confidence: Likely, Line: n}
+	if strings.Contains(s, ",") {
+		s = strings.Replace(s, ",", ".", 1)
+		report = lower(report, Guess, "decimal comma read as a point")
+	}
+
+	price, err := receipt.ParseMoney(s)
+	if err != nil {
+		return 0, FieldReport{Confidence: Missing, Line: n, Note: err.Error()}
+	}
+
+	return price, report
+}
+
+func makeDate(year, month, day string) (string, bool) {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if date.Year() != y || int(date.Month()) != m || date.Day() != d {
+		return "", false
+	}
+
+	return date.Format("2006-01-02"), true
+}
+
+// lower lowers the report's confidence to at most confidence and appends
+// note.
+func lower(report FieldReport, confidence float64, note string) FieldReport {
+	report.Confidence = min(report.Confidence, confidence)
+	if report.Note != "" {
+		note = report.Note + "; " + note
+	}
+	report.Note = note
+
+	return report
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+const cornerMarket = `
+M&M CORNER MARKET
+123 MAIN ST, SPRINGFIELD
+03/20/2022 02:33 PM
+
+GATORADE ........ 2.25
+GATORADE          2.25 N
+SUBTOTAL          4.50
+TAX               0.00
+TOTAL             4.50
+CASH              5.00
+CHANGE            0.50
+`
+
+func TestParse(t *testing.T) {
+	result := Parse(cornerMarket)
+
+	expected := receipt.ReceiptDTO{
+		Retailer:     "M&M CORNER MARKET",
+		PurchaseDate: "2022-03-20",
+		PurchaseTime: "14:33",
+		Total:        450,
+		Items: []receipt.ItemDTO{
+			{ShortDescription: "GATORADE", Price: 225},
+			{ShortDescription: "GATORADE", Price: 225},
+		},
+	}
+
+	got := result.Receipt
+	if got.Retailer != expected.Retailer {
+		t.Errorf("expected retailer %q. got %q", expected.Retailer, got.Retailer)
+	}
+	if got.PurchaseDate != expected.PurchaseDate {
+		t.Errorf("expected purchaseDate %q. got %q", expected.PurchaseDate, got.PurchaseDate)
+	}
+	if got.PurchaseTime != expected.PurchaseTime {
+		t.Errorf("expected purchaseTime %q. got %q", expected.PurchaseTime, got.PurchaseTime)
+	}
+	if got.Total != expected.Total {
+		t.Errorf("expected total %s. got %s", expected.Total, got.Total)
+	}
+	if len(got.Items) != len(expected.Items) {
+		t.Fatalf("expected %d items. got %+v", len(expected.Items), got.Items)
+	}
+	for i, item := range expected.Items {
+		if got.Items[i] != item {
+			t.Errorf("expected item %d to be %+v. got %+v", i, item, got.Items[i])
+		}
+	}
+
+	isValid, errors := got.IsValid(receipt.DefaultValidationPolicy())
+	if !isValid {
+		t.Errorf("expected a valid receipt. got %v", errors)
+	}
+
+	confidences := map[string]float64{
+		"retailer":     Likely,
+		"purchaseDate": Likely,
+		"purchaseTime": Exact,
+		"total":        Exact,
+		"items":        Likely,
+	}
+	for field, confidence := range confidences {
+		if result.Fields[field].Confidence != confidence {
+			t.Errorf("expected %s confidence %.1f. got %+v", field, confidence, result.Fields[field])
+		}
+	}
+	if result.Fields["items[1]"].Line != 7 {
+		t.Errorf("expected items[1] to be read from line 7. got %d", result.Fields["items[1]"].Line)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		field      string
+		value      func(receipt.ReceiptDTO) string
+		expected   string
+		confidence float64
+	}{
+		{
+			name:       "iso date",
+			text:       "TARGET\n2022-01-02 13:13\nPEPSI 1.25\nTOTAL 1.25",
+			field:      "purchaseDate",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.PurchaseDate },
+			expected:   "2022-01-02",
+			confidence: Exact,
+		},
+		{
+			name:       "day first date",
+			text:       "TARGET\n25/12/22 13:13\nPEPSI 1.25\nTOTAL 1.25",
+			field:      "purchaseDate",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.PurchaseDate },
+			expected:   "2022-12-25",
+			confidence: Guess,
+		},
+		{
+			name:       "time on its own line",
+			text:       "TARGET\n2022-01-02\nTIME 12:05 AM\nPEPSI 1.25\nTOTAL 1.25",
+			field:      "purchaseTime",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.PurchaseTime },
+			expected:   "00:05",
+			confidence: Likely,
+		},
+		{
+			name:       "missing time",
+			text:       "TARGET\n2022-01-02\nPEPSI 1.25\nTOTAL 1.25",
+			field:      "purchaseTime",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.PurchaseTime },
+			expected:   "",
+			confidence: Missing,
+		},
+		{
+			name:       "decimal comma",
+			text:       "TARGET\n2022-01-02 13:13\nPEPSI 1,25\nTOTAL 1.25",
+			field:      "items[0]",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.Items[0].Price.String() },
+			expected:   "1.25",
+			confidence: Guess,
+		},
+		{
+			name:       "total not matching the items",
+			text:       "TARGET\n2022-01-02 13:13\nPEPSI 1.25\nTOTAL 1.35",
+			field:      "total",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.Total.String() },
+			expected:   "1.35",
+			confidence: Guess,
+		},
+		{
+			name:       "missing total",
+			text:       "TARGET\n2022-01-02 13:13\nPEPSI 1.25\nDORITOS 2.00",
+			field:      "total",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.Total.String() },
+			expected:   "3.25",
+			confidence: Guess,
+		},
+		{
+			name:       "noisy description",
+			text:       "TARGET\n2022-01-02 13:13\nPEP$I* 1.25\nTOTAL 1.25",
+			field:      "items[0]",
+			value:      func(dto receipt.ReceiptDTO) string { return dto.Items[0].ShortDescription },
+			expected:   "PEP$I*",
+			confidence: Guess,
+		},
+	}
+
+	for _, tt := range tests {
+		result := Parse(tt.text)
+
+		got := tt.value(result.Receipt)
+		if got != tt.expected {
+			t.Errorf("%s: expected %s %q. got %q", tt.name, tt.field, tt.expected, got)
+		}
+		if result.Fields[tt.field].Confidence != tt.confidence {
+			t.Errorf("%s: expected confidence %.1f. got %+v", tt.name, tt.confidence, result.Fields[tt.field])
+		}
+	}
+}
+
+func TestParseAdjustments(t *testing.T) {
+	result := Parse(`TARGET
+2022-01-02 13:13
+PEPSI 10.00
+SUBTOTAL 10.00
+SALES TAX 0.80
+COUPON PEPSI 0.50
+BAG FEE 0.10
+TOTAL 10.40`)
+
+	expected := []receipt.AdjustmentDTO{
+		{Kind: receipt.AdjustmentTax, Description: "SALES TAX", Amount: 80},
+		{Kind: receipt.AdjustmentDiscount, Description: "COUPON PEPSI", Amount: 50},
+		{Kind: receipt.AdjustmentFee, Description: "BAG FEE", Amount: 10},
+	}
+
+	got := result.Receipt.Adjustments
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d adjustments. got %+v", len(expected), got)
+	}
+	for i, adjustment := range expected {
+		if got[i] != adjustment {
+			t.Errorf("expected adjustment %d to be %+v. got %+v", i, adjustment, got[i])
+		}
+	}
+
+	if result.Fields["total"].Confidence != Exact {
+		t.Errorf("expected the total to match the items and adjustments. got %+v", result.Fields["total"])
+	}
+
+	isValid, errors := result.Receipt.IsValid(receipt.DefaultValidationPolicy())
+	if !isValid {
+		t.Errorf("expected a valid receipt. got %v", errors)
+	}
+}
+
+func TestParseQuantities(t *testing.T) {
+	tests := []struct {
+		line       string
+		expected   receipt.ItemDTO
+		confidence float64
+	}{
+		{"3 X GATORADE @ 2.25 6.75", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 675, Quantity: 3, UnitPrice: 225}, Likely},
+		{"GATORADE 3 @ 2.25 6.75", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 675, Quantity: 3, UnitPrice: 225}, Likely},
+		{"3 X GATORADE 6.75", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 675, Quantity: 3, UnitPrice: 225}, Likely},
+		{"3 X GATORADE @ 2.25", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 675, Quantity: 3, UnitPrice: 225}, Likely},
+		{"1 X GATORADE 2.25", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 225}, Likely},
+		{"3 X GATORADE 7.00", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 700}, Guess},
+		{"3 X GATORADE @ 2.25 7.00", receipt.ItemDTO{ShortDescription: "GATORADE", Price: 700, Quantity: 3, UnitPrice: 225}, Guess},
+		{"MOUNTAIN DEW 12PK 6.49", receipt.ItemDTO{ShortDescription: "MOUNTAIN DEW 12PK", Price: 649}, Likely},
+	}
+
+	for _, tt := range tests {
+		result := Parse("TARGET\n2022-01-02 13:13\n" + tt.line)
+
+		if len(result.Receipt.Items) != 1 {
+			t.Errorf("%q: expected 1 item. got %+v", tt.line, result.Receipt.Items)
+			continue
+		}
+		if result.Receipt.Items[0] != tt.expected {
+			t.Errorf("%q: expected %+v. got %+v", tt.line, tt.expected, result.Receipt.Items[0])
+		}
+		if result.Fields["items[0]"].Confidence != tt.confidence {
+			t.Errorf("%q: expected confidence %.1f. got %+v", tt.line, tt.confidence, result.Fields["items[0]"])
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	result := Parse("")
+
+	for _, field := range []string{"retailer", "purchaseDate", "purchaseTime", "total", "items"} {
+		if result.Fields[field].Confidence != Missing {
+			t.Errorf("expected %s to be missing. got %+v", field, result.Fields[field])
+		}
+	}
+	if result.Receipt.Items == nil {
+		t.Error("expected empty items rather than null")
+	}
+}
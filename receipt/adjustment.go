@@ -0,0 +1,107 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+type AdjustmentKind string
+
+const (
+	AdjustmentTax      AdjustmentKind = "tax"
+	AdjustmentDiscount AdjustmentKind = "discount"
+	AdjustmentTip      AdjustmentKind = "tip"
+	AdjustmentFee      AdjustmentKind = "fee"
+)
+
+var adjustmentKinds = []AdjustmentKind{
+	AdjustmentTax,
+	AdjustmentDiscount,
+	AdjustmentTip,
+	AdjustmentFee,
+}
+
+func (k AdjustmentKind) IsValid() bool {
+	for _, kind := range adjustmentKinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adjustmentKindNames lists the adjustment kinds for error messages.
+func adjustmentKindNames() string {
+	names := make([]string, len(adjustmentKinds))
+	for i, kind := range adjustmentKinds {
+		names[i] = string(kind)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Adjustment is a receipt line that isn't an item, such as a tax or a
+// coupon. Amount is always positive, discounts are subtracted from the total
+// and every other kind is added to it.
+type Adjustment struct {
+	Kind        AdjustmentKind `json:"kind"`
+	Description string         `json:"description,omitempty"`
+	Amount      Money          `json:"amount"`
+}
+
+// Signed returns the amount the adjustment adds to the total.
+func (a Adjustment) Signed() Money {
+	if a.Kind == AdjustmentDiscount {
+		return -a.Amount
+	}
+
+	return a.Amount
+}
+
+type AdjustmentDTO struct {
+	Kind        AdjustmentKind `json:"kind"`
+	Description string         `json:"description,omitempty"`
+	Amount      Money          `json:"amount"`
+}
+
+// validate checks the adjustment at path, the errors of each field are
+// reported under its own path.
+func (dto AdjustmentDTO) validate(v *validator.Validator, path string, policy ValidationPolicy) {
+	v.Check(
+		dto.Kind.IsValid(),
+		validator.Field(path, "kind"),
+		fmt.Sprintf("unknown kind %q, valid kinds are %s", dto.Kind, adjustmentKindNames()),
+	)
+	v.Check(
+		len(dto.Description) <= policy.DescriptionMaxLength,
+		validator.Field(path, "description"),
+		fmt.Sprintf("description max length is %d characters", policy.DescriptionMaxLength),
+	)
+	v.Check(
+		dto.Amount > 0,
+		validator.Field(path, "amount"),
+		"amount must be greater than 0.00, discounts are subtracted by kind",
+	)
+}
+
+// Subtotal returns the sum of the item prices.
+func (r Receipt) Subtotal() Money {
+	var subtotal Money
+	for _, item := range r.Items {
+		subtotal += item.Price
+	}
+
+	return subtotal
+}
+
+// amount returns the amount rules scoring on basis look at.
+func (r Receipt) amount(basis Basis) Money {
+	if basis == BasisSubtotal {
+		return r.Subtotal()
+	}
+
+	return r.Total
+}
@@ -0,0 +1,104 @@
+package receipt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTotalWithAdjustments(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       Money
+		adjustments []AdjustmentDTO
+		expectedKey string
+	}{
+		{
+			name:  "no adjustments",
+			total: money("10.00"),
+		},
+		{
+			name:  "tax and discount",
+			total: money("10.30"),
+			adjustments: []AdjustmentDTO{
+				{Kind: AdjustmentTax, Amount: money("0.80")},
+				{Kind: AdjustmentDiscount, Description: "COUPON", Amount: money("0.50")},
+			},
+		},
+		{
+			name:  "tip and fee",
+			total: money("12.10"),
+			adjustments: []AdjustmentDTO{
+				{Kind: AdjustmentTip, Amount: money("2.00")},
+				{Kind: AdjustmentFee, Description: "BAG FEE", Amount: money("0.10")},
+			},
+		},
+		{
+			name:        "total without the tax",
+			total:       money("10.00"),
+			adjustments: []AdjustmentDTO{{Kind: AdjustmentTax, Amount: money("0.80")}},
+			expectedKey: "total",
+		},
+		{
+			name:        "unknown kind",
+			total:       money("10.80"),
+			adjustments: []AdjustmentDTO{{Kind: "surcharge", Amount: money("0.80")}},
+			expectedKey: "adjustments[0].kind",
+		},
+		{
+			name:        "negative discount",
+			total:       money("9.50"),
+			adjustments: []AdjustmentDTO{{Kind: AdjustmentDiscount, Amount: money("-0.50")}},
+			expectedKey: "adjustments[0].amount",
+		},
+	}
+
+	for _, tt := range tests {
+		dto := ReceiptDTO{
+			Retailer:     "Target",
+			PurchaseDate: "2022-01-02",
+			PurchaseTime: "13:13",
+			Total:        tt.total,
+			Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("10.00")}},
+			Adjustments:  tt.adjustments,
+		}
+
+		isValid, errors := dto.IsValid(DefaultValidationPolicy())
+		if tt.expectedKey == "" {
+			if !isValid {
+				t.Errorf("%s: expected a valid receipt. got %v", tt.name, errors)
+			}
+			continue
+		}
+		if _, ok := errors[tt.expectedKey]; !ok {
+			t.Errorf("%s: expected an error for %q. got %v", tt.name, tt.expectedKey, errors)
+		}
+	}
+}
+
+func TestRuleBasis(t *testing.T) {
+	r := Receipt{
+		Total:       money("10.80"),
+		PurchasedAt: time.Date(2022, time.January, 2, 13, 13, 0, 0, time.UTC),
+		Items:       []Item{{ShortDescription: "Pepsi - 12-oz", Price: money("10.00")}},
+		Adjustments: []Adjustment{{Kind: AdjustmentTax, Amount: money("0.80")}},
+	}
+
+	tests := []struct {
+		rule     Rule
+		expected int
+	}{
+		{Rule{Kind: RuleRoundDollar, Points: 50}, 0},
+		{Rule{Kind: RuleRoundDollar, Points: 50, Params: RuleParams{Basis: BasisTotal}}, 0},
+		{Rule{Kind: RuleRoundDollar, Points: 50, Params: RuleParams{Basis: BasisSubtotal}}, 50},
+		{Rule{Kind: RuleTotalMultipleOf, Points: 25, Params: RuleParams{Multiple: money("0.20")}}, 25},
+		{Rule{Kind: RuleTotalMultipleOf, Points: 25, Params: RuleParams{Multiple: money("0.25"), Basis: BasisSubtotal}}, 25},
+		{Rule{Kind: RuleTotalMultipleOf, Points: 25, Params: RuleParams{Multiple: money("0.25")}}, 0},
+	}
+
+	for _, tt := range tests {
+		got := tt.rule.Apply(r)
+		if got != tt.expected {
+			t.Errorf("%s on %s: expected %d points. got %d", tt.rule.Kind, tt.rule.Params.Basis.label(), tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// Revision is a previous version of an amended receipt.
+type Revision struct {
+	// Revision numbers the versions of a receipt from 1, the oldest.
+	Revision int     `json:"revision"`
+	Receipt  Receipt `json:"receipt"`
+
+	// SupersededAt is when the amendment that replaced this version was
+	// made.
+	SupersededAt time.Time `json:"supersededAt"`
+}
+
+// DTO returns the ReceiptDTO that describes the receipt, items keep their
+// category so amending the receipt doesn't classify them again.
+func (r Receipt) DTO() ReceiptDTO {
+	dto := ReceiptDTO{
+		Retailer:     r.Retailer,
+		PurchaseDate: r.PurchasedAt.Format("2006-01-02"),
+		PurchaseTime: r.PurchasedAt.Format("15:04"),
+		Timezone:     r.Timezone,
+		Total:        r.Total,
+		Items:        make([]ItemDTO, len(r.Items)),
+	}
+
+	for i, item := range r.Items {
+		dto.Items[i] = ItemDTO{
+			ShortDescription: item.ShortDescription,
+			Price:            item.Price,
+			Quantity:         item.Quantity,
+			UnitPrice:        item.UnitPrice,
+			Category:         item.Category,
+		}
+	}
+
+	for _, adjustment := range r.Adjustments {
+		dto.Adjustments = append(dto.Adjustments, AdjustmentDTO(adjustment))
+	}
+
+	return dto
+}
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) to dto. Arrays such
+// as items are replaced as a whole.
+func applyMergePatch(dto ReceiptDTO, patch map[string]any) (ReceiptDTO, error) {
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return ReceiptDTO{}, err
+	}
+
+	var document map[string]any
+	err = json.Unmarshal(b, &document)
+	if err != nil {
+		return ReceiptDTO{}, err
+	}
+
+	b, err = json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return ReceiptDTO{}, err
+	}
+
+	var patched ReceiptDTO
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(&patched)
+	if err != nil {
+		return ReceiptDTO{}, patchError(err)
+	}
+
+	return patched, nil
+}
+
+// mergePatch merges patch into target: null members remove the target's,
+// objects are merged recursively and any other value replaces the target's.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// patchError turns the error decoding a patched receipt into a client facing
+// error.
+func patchError(err error) error {
+	var (
+		unmarshalTypeError *json.UnmarshalTypeError
+		invalidMoneyError  *InvalidMoneyError
+	)
+
+	switch {
+	case errors.As(err, &unmarshalTypeError):
+		return errs.Errorf(
+			errs.EINVALID,
+			"patch contains incorrect JSON type for field %q",
+			unmarshalTypeError.Field,
+		)
+
+	case errors.As(err, &invalidMoneyError):
+		return errs.Errorf(errs.EINVALID, "patch contains %s", invalidMoneyError.Error())
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return errs.Errorf(
+			errs.EINVALID,
+			"patch contains unknown key %s",
+			strings.TrimPrefix(err.Error(), "json: unknown field "),
+		)
+
+	default:
+		return errs.Errorf(errs.EINTERNAL, "%s", err.Error())
+	}
+}
@@ -0,0 +1,121 @@
+package receipt
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		patch    string
+		expected string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"merge nested object", `{"a":{"b":"c","d":"e"}}`, `{"a":{"d":null,"f":"g"}}`, `{"a":{"b":"c","f":"g"}}`},
+		{"object replaces value", `{"a":"b"}`, `{"a":{"c":"d"}}`, `{"a":{"c":"d"}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decode := func(s string) any {
+				var v any
+				if err := json.Unmarshal([]byte(s), &v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return v
+			}
+
+			expected := decode(test.expected)
+			got := mergePatch(decode(test.target), decode(test.patch))
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %v. got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repository := &batchRepository{}
+	service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	original, err := service.Process(context.Background(), dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	patch := map[string]any{
+		"total": "3.00",
+		"items": []any{map[string]any{"shortDescription": "Pepsi - 12-oz", "price": "3.00"}},
+	}
+	patched, err := service.Patch(context.Background(), original.ID, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if patched.ID != original.ID {
+		t.Errorf("expected the receipt to keep its ID %s. got %s", original.ID, patched.ID)
+	}
+	if !patched.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("expected the receipt to keep its creation time %v. got %v", original.CreatedAt, patched.CreatedAt)
+	}
+	if patched.Retailer != "Target" {
+		t.Errorf("expected the members left out of the patch to be kept, retailer Target. got %s", patched.Retailer)
+	}
+	if patched.Total != money("3.00") {
+		t.Errorf("expected total 3.00. got %s", patched.Total)
+	}
+	expectedPoints := DefaultRuleset().Score(*patched)
+	if *patched.Points != expectedPoints || expectedPoints == *original.Points {
+		t.Errorf("expected the receipt to be rescored, %d points. got %d", expectedPoints, *patched.Points)
+	}
+
+	tests := []struct {
+		name  string
+		patch map[string]any
+		code  string
+	}{
+		{"unknown key", map[string]any{"store": "Walmart"}, errs.EINVALID},
+		{"incorrect type", map[string]any{"items": "Pepsi"}, errs.EINVALID},
+		{"removed required member", map[string]any{"retailer": nil}, errs.EINVALID},
+		{"duplicate of another receipt", map[string]any{"retailer": "Walmart"}, errs.ECONFLICT},
+	}
+
+	walmart := dto
+	walmart.Retailer = "Walmart"
+	walmart.Total = money("3.00")
+	walmart.Items = []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("3.00")}}
+	_, err = service.Process(context.Background(), walmart)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := service.Patch(context.Background(), original.ID, test.patch)
+			if errs.ErrorCode(err) != test.code {
+				t.Errorf("expected error code %s. got %v", test.code, err)
+			}
+		})
+	}
+}
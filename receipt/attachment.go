@@ -0,0 +1,48 @@
+package receipt
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Attachment is a file attached to a receipt, such as the photo of the
+// paper receipt. Its content is kept in a BlobStore under SHA256.
+type Attachment struct {
+	ID          string    `json:"id"`
+	ReceiptID   string    `json:"receiptID"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type AttachmentRepository interface {
+	FindById(ctx context.Context, receiptID, id string) (*Attachment, error)
+	FindBySHA256(ctx context.Context, receiptID, sha256 string) (*Attachment, error)
+	Create(ctx context.Context, attachment *Attachment) error
+	IsReferenced(ctx context.Context, sha256 string) (bool, error)
+}
+
+// BlobStore keeps content addressed by its SHA-256, the hex encoded SHA-256
+// is the key Put returns.
+type BlobStore interface {
+	Put(ctx context.Context, r io.Reader) (string, int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// attachmentContentTypes are the accepted attachment formats, detected from
+// the content of the file.
+var attachmentContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"application/pdf",
+}
+
+func attachmentContentTypeNames() string {
+	return strings.Join(attachmentContentTypes, ", ")
+}
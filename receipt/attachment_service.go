@@ -0,0 +1,209 @@
+package receipt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// errAttachmentTooLarge stops a BlobStore from storing a file larger than
+// the limit.
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+type AttachmentService struct {
+	repository AttachmentRepository
+	receipts   ReceiptRepository
+	blobs      BlobStore
+	maxBytes   int64
+
+	// content is read locked from storing an upload's content until its
+	// attachment references it, and write locked by DeleteContent, so content
+	// found unreferenced isn't reused by an upload while it is deleted.
+	content *sync.RWMutex
+}
+
+func NewAttachmentService(
+	repository AttachmentRepository,
+	receipts ReceiptRepository,
+	blobs BlobStore,
+	maxBytes int64,
+) AttachmentService {
+	return AttachmentService{
+		repository,
+		receipts,
+		blobs,
+		maxBytes,
+		&sync.RWMutex{},
+	}
+}
+
+// Upload attaches the content of r to the receipt. The content type is
+// detected from the content, whatever the client declared. Uploading a file
+// the receipt already has returns the existing attachment and false.
+func (s *AttachmentService) Upload(
+	ctx context.Context,
+	receiptID string,
+	filename string,
+	r io.Reader,
+) (*Attachment, bool, error) {
+	err := uuid.Validate(receiptID)
+	if err != nil {
+		return nil, false, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	rec, err := s.receipts.FindById(ctx, receiptID)
+	if err != nil {
+		return nil, false, err
+	}
+	if rec.DeletedAt != nil {
+		return nil, false, &errs.Error{
+			Code:    errs.ECONFLICT,
+			Message: "Deleted receipts can't get attachments, restore the receipt first",
+		}
+	}
+
+	filename = path.Base(strings.ReplaceAll(filename, `\`, "/"))
+	if filename == "." || filename == "/" {
+		filename = ""
+	}
+	if len(filename) > 255 {
+		return nil, false, invalidAttachment("file name max length is 255 characters")
+	}
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, false, err
+	}
+	if len(head) == 0 {
+		return nil, false, invalidAttachment("file cannot be empty")
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !slices.Contains(attachmentContentTypes, contentType) {
+		return nil, false, invalidAttachment(fmt.Sprintf(
+			"unsupported file type %s, it should be one of: %s",
+			contentType,
+			attachmentContentTypeNames(),
+		))
+	}
+
+	s.content.RLock()
+	defer s.content.RUnlock()
+
+	key, size, err := s.blobs.Put(ctx, &limitedReader{br, s.maxBytes})
+	if err != nil {
+		if errors.Is(err, errAttachmentTooLarge) {
+			return nil, false, invalidAttachment(fmt.Sprintf("file must not be larger than %d bytes", s.maxBytes))
+		}
+		return nil, false, err
+	}
+
+	existing, err := s.repository.FindBySHA256(ctx, receiptID, key)
+	if err == nil {
+		return existing, false, nil
+	}
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		return nil, false, err
+	}
+
+	attachment := &Attachment{
+		ID:          uuid.New().String(),
+		ReceiptID:   receiptID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      key,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+
+	err = s.repository.Create(ctx, attachment)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return attachment, true, nil
+}
+
+// Open returns the attachment of the receipt and its content, the caller
+// must close the content.
+func (s *AttachmentService) Open(ctx context.Context, receiptID, id string) (*Attachment, io.ReadCloser, error) {
+	if uuid.Validate(receiptID) != nil || uuid.Validate(id) != nil {
+		return nil, nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Attachment not found"}
+	}
+
+	attachment, err := s.repository.FindById(ctx, receiptID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobs.Open(ctx, attachment.SHA256)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteContent deletes the content of attachments that were removed, unless
+// another attachment still references the same content.
+func (s *AttachmentService) DeleteContent(ctx context.Context, attachments []Attachment) error {
+	s.content.Lock()
+	defer s.content.Unlock()
+
+	for _, attachment := range attachments {
+		referenced, err := s.repository.IsReferenced(ctx, attachment.SHA256)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			continue
+		}
+
+		err = s.blobs.Delete(ctx, attachment.SHA256)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func invalidAttachment(message string) error {
+	return &errs.Error{
+		Code:    errs.EINVALID,
+		Message: "Invalid field/s",
+		Details: map[string][]string{
+			"file": {message},
+		},
+	}
+}
+
+// limitedReader reads from r until more than n bytes were read, then fails
+// with errAttachmentTooLarge.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errAttachmentTooLarge
+	}
+
+	return n, err
+}
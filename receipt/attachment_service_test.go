@@ -0,0 +1,189 @@
+package receipt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+type memoryAttachments struct {
+	AttachmentRepository
+	created []*Attachment
+}
+
+func (m *memoryAttachments) FindBySHA256(ctx context.Context, receiptID, sha256 string) (*Attachment, error) {
+	for _, attachment := range m.created {
+		if attachment.ReceiptID == receiptID && attachment.SHA256 == sha256 {
+			return attachment, nil
+		}
+	}
+
+	return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Attachment not found"}
+}
+
+func (m *memoryAttachments) Create(ctx context.Context, attachment *Attachment) error {
+	m.created = append(m.created, attachment)
+	return nil
+}
+
+type memoryBlobs struct {
+	BlobStore
+	blobs map[string][]byte
+}
+
+func (m *memoryBlobs) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", 0, err
+	}
+
+	sum := sha256.Sum256(b)
+	key := hex.EncodeToString(sum[:])
+	m.blobs[key] = b
+
+	return key, int64(len(b)), nil
+}
+
+func TestUploadAttachment(t *testing.T) {
+	rec := &Receipt{ID: "6b3d8a4e-5f0c-4b9b-9a57-2d0f1c7e8a10"}
+	deleted := time.Now()
+	deletedRec := &Receipt{ID: "0c7e2b7a-3f1d-4e55-8a0e-9c1b5d7f2e31", DeletedAt: &deleted}
+
+	attachments := &memoryAttachments{}
+	blobs := &memoryBlobs{blobs: map[string][]byte{}}
+	service := NewAttachmentService(
+		attachments,
+		&batchRepository{created: []*Receipt{rec, deletedRec}},
+		blobs,
+		1024,
+	)
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 100)
+
+	tests := []struct {
+		name      string
+		receiptID string
+		content   string
+		code      string
+		created   bool
+	}{
+		{"png", rec.ID, png, "", true},
+		{"same png again", rec.ID, png, "", false},
+		{"pdf", rec.ID, "%PDF-1.7\n" + strings.Repeat("a", 100), "", true},
+		{"unsupported type", rec.ID, "total 1.25", errs.EINVALID, false},
+		{"empty file", rec.ID, "", errs.EINVALID, false},
+		{"too large", rec.ID, png + strings.Repeat("\x00", 1024), errs.EINVALID, false},
+		{"deleted receipt", deletedRec.ID, png, errs.ECONFLICT, false},
+		{"unknown receipt", "d1f0b9a2-8c4e-4f3b-b6a7-5e2c9d8f1a04", png, errs.ENOTFOUND, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attachment, created, err := service.Upload(
+				context.Background(),
+				test.receiptID,
+				`C:\photos\receipt.png`,
+				bytes.NewReader([]byte(test.content)),
+			)
+			if errs.ErrorCode(err) != test.code {
+				t.Fatalf("expected error code %q. got %v", test.code, err)
+			}
+			if created != test.created {
+				t.Errorf("expected created to be %t. got %t", test.created, created)
+			}
+			if err == nil && attachment.Filename != "receipt.png" {
+				t.Errorf("expected the file name without its directory, receipt.png. got %s", attachment.Filename)
+			}
+		})
+	}
+
+	if len(attachments.created) != 2 || len(blobs.blobs) != 2 {
+		t.Errorf("expected 2 attachments and blobs. got %d and %d", len(attachments.created), len(blobs.blobs))
+	}
+	if attachments.created[0].ContentType != "image/png" || attachments.created[1].ContentType != "application/pdf" {
+		t.Errorf(
+			"expected content types image/png and application/pdf. got %s and %s",
+			attachments.created[0].ContentType,
+			attachments.created[1].ContentType,
+		)
+	}
+}
+
+func (m *memoryAttachments) IsReferenced(ctx context.Context, sha256 string) (bool, error) {
+	for _, attachment := range m.created {
+		if attachment.SHA256 == sha256 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *memoryBlobs) Delete(ctx context.Context, key string) error {
+	delete(m.blobs, key)
+	return nil
+}
+
+// slowAttachments signals creating when an attachment is about to be created
+// and waits for release to create it.
+type slowAttachments struct {
+	*memoryAttachments
+	creating chan struct{}
+	release  chan struct{}
+}
+
+func (s slowAttachments) Create(ctx context.Context, attachment *Attachment) error {
+	close(s.creating)
+	<-s.release
+	return s.memoryAttachments.Create(ctx, attachment)
+}
+
+func TestDeleteContentWaitsForUpload(t *testing.T) {
+	rec := &Receipt{ID: "6b3d8a4e-5f0c-4b9b-9a57-2d0f1c7e8a10"}
+	attachments := slowAttachments{&memoryAttachments{}, make(chan struct{}), make(chan struct{})}
+	blobs := &memoryBlobs{blobs: map[string][]byte{}}
+	service := NewAttachmentService(attachments, &batchRepository{created: []*Receipt{rec}}, blobs, 1024)
+
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 100))
+	sum := sha256.Sum256(png)
+	key := hex.EncodeToString(sum[:])
+
+	uploaded := make(chan error)
+	go func() {
+		_, _, err := service.Upload(context.Background(), rec.ID, "receipt.png", bytes.NewReader(png))
+		uploaded <- err
+	}()
+	<-attachments.creating
+
+	// The content is stored but not referenced yet, a purged attachment with
+	// the same content must not delete it.
+	deleted := make(chan error)
+	go func() {
+		deleted <- service.DeleteContent(context.Background(), []Attachment{{SHA256: key}})
+	}()
+
+	select {
+	case <-deleted:
+		t.Fatalf("expected DeleteContent to wait for the upload")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(attachments.release)
+	if err := <-uploaded; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-deleted; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := blobs.blobs[key]; !ok {
+		t.Errorf("expected the uploaded content to be kept")
+	}
+}
@@ -0,0 +1,24 @@
+package receipt
+
+// BatchResult is the outcome of processing one receipt of a batch, Index is
+// its position in the batch. Receipts that fail validation carry Errors and
+// are not stored.
+type BatchResult struct {
+	Index  int                 `json:"index"`
+	ID     string              `json:"id,omitempty"`
+	Points *int                `json:"points,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"`
+}
+
+// BatchResults counts the created and failed receipts of a batch.
+func BatchResults(results []BatchResult) (created, failed int) {
+	for _, result := range results {
+		if result.Errors != nil {
+			failed++
+		} else {
+			created++
+		}
+	}
+
+	return created, failed
+}
@@ -0,0 +1,176 @@
+package receipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+type batchRepository struct {
+	ReceiptRepository
+	created []*Receipt
+}
+
+func (r *batchRepository) CreateBatch(ctx context.Context, receipts []*Receipt) error {
+	r.created = append(r.created, receipts...)
+	return nil
+}
+
+func (r *batchRepository) Create(ctx context.Context, receipt *Receipt) error {
+	r.created = append(r.created, receipt)
+	return nil
+}
+
+func (r *batchRepository) FindById(ctx context.Context, id string) (*Receipt, error) {
+	for _, rec := range r.created {
+		if rec.ID == id {
+			return rec, nil
+		}
+	}
+
+	return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+}
+
+func (r *batchRepository) Update(ctx context.Context, receipt *Receipt) error {
+	for i, rec := range r.created {
+		if rec.ID == receipt.ID {
+			r.created[i] = receipt
+			return nil
+		}
+	}
+
+	return &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+}
+
+func (r *batchRepository) Delete(ctx context.Context, id, reason string, at time.Time) error {
+	rec, err := r.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	rec.DeletedAt = &at
+	rec.DeleteReason = reason
+
+	return nil
+}
+
+func (r *batchRepository) FindIdByFingerprint(ctx context.Context, fingerprint string) (string, error) {
+	for _, rec := range r.created {
+		if rec.Fingerprint == fingerprint && rec.DuplicateOf == "" {
+			return rec.ID, nil
+		}
+	}
+
+	return "", &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+}
+
+type campaignsStub struct {
+	CampaignRepository
+	campaigns []Campaign
+}
+
+func (c campaignsStub) Find(ctx context.Context) ([]Campaign, error) {
+	return c.campaigns, nil
+}
+
+func (c campaignsStub) FindActive(ctx context.Context, at time.Time) ([]Campaign, error) {
+	active := []Campaign{}
+	for _, campaign := range c.campaigns {
+		if campaign.IsActive(at) {
+			active = append(active, campaign)
+		}
+	}
+
+	return active, nil
+}
+
+type nopCache struct {
+	ReceiptCache
+}
+
+func (nopCache) SetScoreById(ctx context.Context, id string, score Score, exp time.Duration) error {
+	return nil
+}
+
+func (nopCache) GetScoreById(ctx context.Context, id string) (Score, error) {
+	return Score{}, &errs.Error{Code: errs.ENOTFOUND, Message: "Score not found in cache"}
+}
+
+func (nopCache) GetReceiptById(ctx context.Context, id string) (*Receipt, error) {
+	return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found in cache"}
+}
+
+func (nopCache) SetReceiptById(ctx context.Context, receipt *Receipt, exp time.Duration) error {
+	return nil
+}
+
+func (nopCache) DeleteReceiptById(ctx context.Context, id string) error {
+	return nil
+}
+
+func (nopCache) DeletePaginatedReceipts(ctx context.Context) error {
+	return nil
+}
+
+func TestProcessBatch(t *testing.T) {
+	repository := &batchRepository{}
+	campaigns := campaignsStub{campaigns: []Campaign{{
+		ID:       "campaign",
+		Name:     "target bonus",
+		StartsAt: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Retailer: "Target",
+		Bonus:    100,
+	}}}
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service := NewService(repository, campaigns, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	valid := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	invalid := valid
+	invalid.Retailer = ""
+	outsideCampaign := valid
+	outsideCampaign.PurchaseDate = "2022-03-02"
+
+	results, err := service.ProcessBatch(context.Background(), []ReceiptDTO{valid, invalid, outsideCampaign})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results. got %d", len(results))
+	}
+	if len(repository.created) != 2 {
+		t.Fatalf("expected 2 stored receipts. got %d", len(repository.created))
+	}
+
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("expected index %d. got %d", i, result.Index)
+		}
+	}
+
+	if results[0].ID != repository.created[0].ID || *results[0].Points != 131 {
+		t.Errorf("expected receipt 0 stored with 131 points. got %+v", results[0])
+	}
+	if results[1].ID != "" || len(results[1].Errors["retailer"]) == 0 {
+		t.Errorf("expected receipt 1 to fail on retailer. got %+v", results[1])
+	}
+	if results[2].ID != repository.created[1].ID || *results[2].Points != 31 {
+		t.Errorf("expected receipt 2 stored with 31 points. got %+v", results[2])
+	}
+
+	created, failed := BatchResults(results)
+	if created != 2 || failed != 1 {
+		t.Errorf("expected 2 created and 1 failed. got %d and %d", created, failed)
+	}
+}
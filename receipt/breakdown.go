@@ -0,0 +1,150 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RuleResult struct {
+	Rule   string   `json:"rule"`
+	Kind   RuleKind `json:"kind"`
+	Points int      `json:"points"`
+	Reason string   `json:"reason"`
+}
+
+type PointsBreakdown struct {
+	Points         int               `json:"points"`
+	RulesetVersion string            `json:"rulesetVersion"`
+	Rules          []RuleResult      `json:"rules"`
+	Campaigns      []AppliedCampaign `json:"campaigns"`
+	DuplicateOf    string            `json:"duplicateOf,omitempty"`
+}
+
+// Breakdown scores the receipt rule by rule, explaining each result.
+func (rs Ruleset) Breakdown(r Receipt) PointsBreakdown {
+	breakdown := PointsBreakdown{
+		RulesetVersion: rs.Version,
+		Rules:          make([]RuleResult, 0, len(rs.Rules)),
+		Campaigns:      []AppliedCampaign{},
+	}
+
+	for _, rule := range rs.Rules {
+		points := rule.Apply(r)
+		breakdown.Points += points
+		breakdown.Rules = append(breakdown.Rules, RuleResult{
+			Rule:   rule.Name,
+			Kind:   rule.Kind,
+			Points: points,
+			Reason: rule.Explain(r, points),
+		})
+	}
+
+	return breakdown
+}
+
+// zeroBreakdown is the breakdown of a duplicate receipt that scores nothing.
+func zeroBreakdown(version, originalID string) PointsBreakdown {
+	return PointsBreakdown{
+		RulesetVersion: version,
+		Rules:          []RuleResult{},
+		Campaigns:      []AppliedCampaign{},
+		DuplicateOf:    originalID,
+	}
+}
+
+// AddCampaigns adds the campaign points on top of the rules points.
+func (b *PointsBreakdown) AddCampaigns(applied []AppliedCampaign) {
+	b.Campaigns = append(b.Campaigns, applied...)
+	b.Points += campaignPoints(applied)
+}
+
+// Explain returns a human-readable reason for the points the rule awarded.
+func (rule Rule) Explain(r Receipt, points int) string {
+	awarded := points > 0
+
+	switch rule.Kind {
+	case RuleRetailerName:
+		count := 0
+		for _, char := range r.Retailer {
+			if isAlphanumeric(char) {
+				count++
+			}
+		}
+		return fmt.Sprintf(
+			"retailer name %q has %d alphanumeric characters, %d points each",
+			r.Retailer, count, rule.Points,
+		)
+
+	case RuleRoundDollar:
+		basis, amount := rule.Params.Basis.label(), r.amount(rule.Params.Basis)
+		if awarded {
+			return fmt.Sprintf("%s %s is a round dollar amount with no cents", basis, amount)
+		}
+		return fmt.Sprintf("%s %s is not a round dollar amount", basis, amount)
+
+	case RuleTotalMultipleOf:
+		basis, amount := rule.Params.Basis.label(), r.amount(rule.Params.Basis)
+		if awarded {
+			return fmt.Sprintf("%s %s is a multiple of %s", basis, amount, rule.Params.Multiple)
+		}
+		return fmt.Sprintf("%s %s is not a multiple of %s", basis, amount, rule.Params.Multiple)
+
+	case RuleEveryNItems:
+		n := r.itemCount(rule.Params.Count)
+		groups := n / rule.Params.Every
+		return fmt.Sprintf(
+			"%d %s make %d groups of %d, %d points per group",
+			n, rule.Params.Count.label(), groups, rule.Params.Every, rule.Points,
+		)
+
+	case RuleOddPurchaseDay:
+		day := r.PurchasedAt.Day()
+		if awarded {
+			return fmt.Sprintf("purchase day %d is odd", day)
+		}
+		return fmt.Sprintf("purchase day %d is even", day)
+
+	case RuleItemDescription:
+		matching := make([]string, 0, len(r.Items))
+		for _, item := range r.Items {
+			trimmed := strings.TrimSpace(item.ShortDescription)
+			if len(trimmed)%rule.Params.LengthMultiple == 0 {
+				matching = append(matching, fmt.Sprintf("%q", trimmed))
+			}
+		}
+		if len(matching) == 0 {
+			return fmt.Sprintf(
+				"no item description has a trimmed length that is a multiple of %d",
+				rule.Params.LengthMultiple,
+			)
+		}
+		return fmt.Sprintf(
+			"items %s have a trimmed description length that is a multiple of %d, each earns its price multiplied by %g rounded up",
+			strings.Join(matching, ", "), rule.Params.LengthMultiple, rule.Params.PriceMultiplier,
+		)
+
+	case RuleItemCategory:
+		return fmt.Sprintf(
+			"%d %s in category %s, %d points each",
+			r.categoryCount(rule.Params.Count, rule.Params.Category),
+			rule.Params.Count.label(),
+			rule.Params.Category,
+			rule.Points,
+		)
+
+	case RuleTimeOfPurchase:
+		purchasedAt := r.PurchasedAt.Format("15:04")
+		if awarded {
+			return fmt.Sprintf(
+				"purchased at %s, after %s and before %s",
+				purchasedAt, rule.Params.Start, rule.Params.End,
+			)
+		}
+		return fmt.Sprintf(
+			"purchased at %s, outside the window after %s and before %s",
+			purchasedAt, rule.Params.Start, rule.Params.End,
+		)
+	}
+
+	panic("unknown rule kind: " + string(rule.Kind))
+}
@@ -0,0 +1,105 @@
+package receipt
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Campaign is a time-boxed promotion applied on top of the ruleset score. It
+// matches receipts by retailer, by item description keyword or both, and
+// either multiplies the ruleset score or adds a flat bonus.
+type Campaign struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Retailer    string    `json:"retailer,omitempty"`
+	ItemKeyword string    `json:"itemKeyword,omitempty"`
+	Multiplier  float64   `json:"multiplier,omitempty"`
+	Bonus       int       `json:"bonus,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// AppliedCampaign records the points a campaign added to a receipt. The name
+// is copied so the record survives the campaign being deleted.
+type AppliedCampaign struct {
+	CampaignID string `json:"campaignID"`
+	Name       string `json:"name"`
+	Points     int    `json:"points"`
+}
+
+type CampaignRepository interface {
+	Find(ctx context.Context) ([]Campaign, error)
+	FindById(ctx context.Context, id string) (*Campaign, error)
+	FindActive(ctx context.Context, at time.Time) ([]Campaign, error)
+	Create(ctx context.Context, campaign *Campaign) error
+	Update(ctx context.Context, campaign *Campaign) error
+	Delete(ctx context.Context, id string) error
+}
+
+// IsActive reports whether at falls in the campaign's [StartsAt, EndsAt)
+// window.
+func (c Campaign) IsActive(at time.Time) bool {
+	return !at.Before(c.StartsAt) && at.Before(c.EndsAt)
+}
+
+// Matches reports whether the receipt satisfies every matcher the campaign
+// sets. Retailers are compared case-insensitively, item keywords match any
+// part of an item's description.
+func (c Campaign) Matches(r Receipt) bool {
+	if c.Retailer != "" && !strings.EqualFold(strings.TrimSpace(r.Retailer), c.Retailer) {
+		return false
+	}
+
+	if c.ItemKeyword != "" {
+		keyword := strings.ToLower(c.ItemKeyword)
+		for _, item := range r.Items {
+			if strings.Contains(strings.ToLower(item.ShortDescription), keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Points returns the points the campaign adds to a receipt whose ruleset
+// score is base. Multipliers apply to base and round up.
+func (c Campaign) Points(base int) int {
+	if c.Multiplier != 0 {
+		return mulCeil(int64(base), c.Multiplier, 1) - base
+	}
+
+	return c.Bonus
+}
+
+// ApplyCampaigns returns the campaigns active at the receipt's purchase time
+// that match it, along with the points each adds to base.
+func ApplyCampaigns(campaigns []Campaign, r Receipt, base int) []AppliedCampaign {
+	applied := make([]AppliedCampaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		if !campaign.IsActive(r.PurchasedAt) || !campaign.Matches(r) {
+			continue
+		}
+
+		applied = append(applied, AppliedCampaign{
+			CampaignID: campaign.ID,
+			Name:       campaign.Name,
+			Points:     campaign.Points(base),
+		})
+	}
+
+	return applied
+}
+
+func campaignPoints(applied []AppliedCampaign) int {
+	points := 0
+	for _, campaign := range applied {
+		points += campaign.Points
+	}
+
+	return points
+}
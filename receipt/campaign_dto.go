@@ -0,0 +1,78 @@
+package receipt
+
+import (
+	"time"
+
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+type CampaignDTO struct {
+	Name        string  `json:"name"`
+	StartsAt    string  `json:"startsAt"`
+	EndsAt      string  `json:"endsAt"`
+	Retailer    string  `json:"retailer"`
+	ItemKeyword string  `json:"itemKeyword"`
+	Multiplier  float64 `json:"multiplier"`
+	Bonus       int     `json:"bonus"`
+}
+
+func (dto CampaignDTO) IsValid() (bool, map[string][]string) {
+	v := validator.New()
+
+	dto.ValidateName(v)
+	dto.ValidateWindow(v)
+	dto.ValidateMatchers(v)
+	dto.ValidateReward(v)
+
+	return v.Ok(), v.Errors
+}
+
+func (dto CampaignDTO) ValidateName(v *validator.Validator) {
+	const key = "name"
+	const maxLen = 100
+
+	v.Check(dto.Name != "", key, "name cannot be empty")
+	v.Check(len(dto.Name) <= maxLen, key, "name max length is 100 characters")
+}
+
+func (dto CampaignDTO) ValidateWindow(v *validator.Validator) {
+	startsAt, errStart := time.Parse(time.RFC3339, dto.StartsAt)
+	endsAt, errEnd := time.Parse(time.RFC3339, dto.EndsAt)
+
+	v.Check(errStart == nil, "startsAt", "invalid format, it should be RFC 3339")
+	v.Check(errEnd == nil, "endsAt", "invalid format, it should be RFC 3339")
+	v.Check(errStart != nil || errEnd != nil || startsAt.Before(endsAt), "endsAt", "endsAt must be after startsAt")
+}
+
+func (dto CampaignDTO) ValidateMatchers(v *validator.Validator) {
+	v.Check(
+		dto.Retailer != "" || dto.ItemKeyword != "",
+		"retailer",
+		"a retailer or an itemKeyword is required",
+	)
+}
+
+func (dto CampaignDTO) ValidateReward(v *validator.Validator) {
+	const key = "multiplier"
+
+	hasMultiplier := dto.Multiplier != 0
+	hasBonus := dto.Bonus != 0
+
+	v.Check(hasMultiplier != hasBonus, key, "exactly one of multiplier or bonus is required")
+	v.Check(!hasMultiplier || dto.Multiplier > 1, key, "multiplier must be greater than 1")
+	v.Check(!hasBonus || dto.Bonus > 0, "bonus", "bonus must be greater than zero")
+}
+
+// apply copies the DTO onto the campaign, the DTO must be valid.
+func (dto CampaignDTO) apply(campaign *Campaign) {
+	startsAt, _ := time.Parse(time.RFC3339, dto.StartsAt)
+	endsAt, _ := time.Parse(time.RFC3339, dto.EndsAt)
+
+	campaign.Name = dto.Name
+	campaign.StartsAt = startsAt.UTC()
+	campaign.EndsAt = endsAt.UTC()
+	campaign.Retailer = dto.Retailer
+	campaign.ItemKeyword = dto.ItemKeyword
+	campaign.Multiplier = dto.Multiplier
+	campaign.Bonus = dto.Bonus
+}
@@ -0,0 +1,94 @@
+package receipt
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+type CampaignService struct {
+	repository CampaignRepository
+}
+
+func NewCampaignService(repository CampaignRepository) CampaignService {
+	return CampaignService{
+		repository,
+	}
+}
+
+func (s *CampaignService) Create(ctx context.Context, dto CampaignDTO) (*Campaign, error) {
+	isValid, errors := dto.IsValid()
+	if !isValid {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	campaign := &Campaign{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	dto.apply(campaign)
+
+	err := s.repository.Create(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *CampaignService) Update(ctx context.Context, id string, dto CampaignDTO) (*Campaign, error) {
+	campaign, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	isValid, errors := dto.IsValid()
+	if !isValid {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	dto.apply(campaign)
+	campaign.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	err = s.repository.Update(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *CampaignService) GetById(ctx context.Context, id string) (*Campaign, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Campaign not found"}
+	}
+
+	return s.repository.FindById(ctx, id)
+}
+
+func (s *CampaignService) GetCampaigns(ctx context.Context) ([]Campaign, error) {
+	return s.repository.Find(ctx)
+}
+
+func (s *CampaignService) Delete(ctx context.Context, id string) error {
+	err := uuid.Validate(id)
+	if err != nil {
+		return &errs.Error{Code: errs.ENOTFOUND, Message: "Campaign not found"}
+	}
+
+	return s.repository.Delete(ctx, id)
+}
@@ -0,0 +1,99 @@
+package receipt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCampaignPoints(t *testing.T) {
+	tests := []struct {
+		campaign Campaign
+		base     int
+		want     int
+	}{
+		{Campaign{Multiplier: 2}, 28, 28},
+		{Campaign{Multiplier: 1.5}, 27, 14},
+		{Campaign{Multiplier: 1.1}, 10, 1},
+		{Campaign{Bonus: 100}, 28, 100},
+	}
+
+	for _, tt := range tests {
+		if got := tt.campaign.Points(tt.base); got != tt.want {
+			t.Errorf("%+v.Points(%d) = %d, want %d", tt.campaign, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCampaigns(t *testing.T) {
+	rec := Receipt{
+		Retailer:    "Target",
+		PurchasedAt: time.Date(2022, time.November, 3, 10, 30, 0, 0, time.UTC),
+		Items: []Item{
+			{ShortDescription: "Mountain Dew 12PK", Price: money("6.49")},
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+		},
+		Total: money("8.74"),
+	}
+
+	novStart := time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC)
+	novEnd := time.Date(2022, time.November, 8, 0, 0, 0, 0, time.UTC)
+
+	campaigns := []Campaign{
+		{ID: "double", Name: "double at target", StartsAt: novStart, EndsAt: novEnd, Retailer: "target", Multiplier: 2},
+		{ID: "gatorade", Name: "gatorade bonus", StartsAt: novStart, EndsAt: novEnd, ItemKeyword: "GATORADE", Bonus: 100},
+		{ID: "walmart", Name: "walmart only", StartsAt: novStart, EndsAt: novEnd, Retailer: "Walmart", Bonus: 50},
+		{ID: "expired", Name: "october", StartsAt: novStart.AddDate(0, -1, 0), EndsAt: novStart, Retailer: "Target", Bonus: 50},
+		{ID: "both", Name: "target and pizza", StartsAt: novStart, EndsAt: novEnd, Retailer: "Target", ItemKeyword: "pizza", Bonus: 50},
+	}
+
+	applied := ApplyCampaigns(campaigns, rec, 20)
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 applied campaigns. got %+v", applied)
+	}
+	if applied[0].CampaignID != "double" || applied[0].Points != 20 {
+		t.Errorf("expected double campaign to add 20 points. got %+v", applied[0])
+	}
+	if applied[1].CampaignID != "gatorade" || applied[1].Points != 100 {
+		t.Errorf("expected gatorade campaign to add 100 points. got %+v", applied[1])
+	}
+}
+
+func TestCampaignDTOIsValid(t *testing.T) {
+	valid := CampaignDTO{
+		Name:       "double points",
+		StartsAt:   "2022-11-01T00:00:00Z",
+		EndsAt:     "2022-11-08T00:00:00Z",
+		Retailer:   "Target",
+		Multiplier: 2,
+	}
+
+	if ok, errors := valid.IsValid(); !ok {
+		t.Errorf("expected campaign to be valid. got %v", errors)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(dto *CampaignDTO)
+		wantKey string
+	}{
+		{"no matcher", func(dto *CampaignDTO) { dto.Retailer = "" }, "retailer"},
+		{"both rewards", func(dto *CampaignDTO) { dto.Bonus = 10 }, "multiplier"},
+		{"no reward", func(dto *CampaignDTO) { dto.Multiplier = 0 }, "multiplier"},
+		{"negative bonus", func(dto *CampaignDTO) { dto.Multiplier, dto.Bonus = 0, -10 }, "bonus"},
+		{"inverted window", func(dto *CampaignDTO) { dto.EndsAt = "2022-10-01T00:00:00Z" }, "endsAt"},
+		{"bad start", func(dto *CampaignDTO) { dto.StartsAt = "2022-11-01" }, "startsAt"},
+	}
+
+	for _, tt := range tests {
+		dto := valid
+		tt.mutate(&dto)
+		ok, errors := dto.IsValid()
+		if ok {
+			t.Errorf("%s: expected campaign to be invalid", tt.name)
+			continue
+		}
+		if _, exists := errors[tt.wantKey]; !exists {
+			t.Errorf("%s: expected error for key %q. got %v", tt.name, tt.wantKey, errors)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package receipt
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+type Category string
+
+const (
+	CategoryProduce      Category = "produce"
+	CategoryDairy        Category = "dairy"
+	CategoryBakery       Category = "bakery"
+	CategoryMeat         Category = "meat"
+	CategoryBeverages    Category = "beverages"
+	CategorySnacks       Category = "snacks"
+	CategoryFrozen       Category = "frozen"
+	CategoryHousehold    Category = "household"
+	CategoryPersonalCare Category = "personal_care"
+	CategoryOther        Category = "other"
+)
+
+// categories is the taxonomy, in the order summaries list them.
+var categories = []Category{
+	CategoryProduce,
+	CategoryDairy,
+	CategoryBakery,
+	CategoryMeat,
+	CategoryBeverages,
+	CategorySnacks,
+	CategoryFrozen,
+	CategoryHousehold,
+	CategoryPersonalCare,
+	CategoryOther,
+}
+
+func (c Category) IsValid() bool {
+	return slices.Contains(categories, c)
+}
+
+// categoryNames lists the taxonomy for error messages.
+func categoryNames() string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = string(category)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// CategorySummary counts a receipt's items in a category and adds up their
+// prices.
+type CategorySummary struct {
+	Category Category `json:"category"`
+	Items    int      `json:"items"`
+	Total    Money    `json:"total"`
+}
+
+// SummarizeCategories returns a summary for every category present in items,
+// in taxonomy order.
+func SummarizeCategories(items []Item) []CategorySummary {
+	summaries := make([]CategorySummary, 0, len(items))
+
+	for _, category := range categories {
+		summary := CategorySummary{Category: category}
+		for _, item := range items {
+			if item.Category == category {
+				summary.Items++
+				summary.Total += item.Price
+			}
+		}
+		if summary.Items > 0 {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return summaries
+}
+
+// Classifier assigns a category to an item description by keyword. Keywords
+// match whole words, case-insensitively, and the longest matching keyword
+// wins, so "orange juice" can be a beverage while "orange" is produce.
+type Classifier struct {
+	keywords map[Category][]string
+}
+
+//go:embed categories/default.json
+var defaultClassifierJSON []byte
+
+var defaultClassifier = sync.OnceValue(func() Classifier {
+	classifier, err := ParseClassifier(defaultClassifierJSON)
+	if err != nil {
+		panic("invalid default category dictionary: " + err.Error())
+	}
+	return classifier
+})
+
+// DefaultClassifier returns the classifier built from the built-in keyword
+// dictionary.
+func DefaultClassifier() Classifier {
+	return defaultClassifier()
+}
+
+// LoadClassifier reads and validates a JSON keyword dictionary file.
+func LoadClassifier(path string) (Classifier, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Classifier{}, fmt.Errorf("failed to read category dictionary file: %w", err)
+	}
+
+	return ParseClassifier(b)
+}
+
+// ParseClassifier decodes and validates a JSON keyword dictionary, an object
+// mapping each category to its keywords.
+func ParseClassifier(b []byte) (Classifier, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+
+	var keywords map[Category][]string
+	if err := decoder.Decode(&keywords); err != nil {
+		return Classifier{}, fmt.Errorf("failed to decode category dictionary: %w", err)
+	}
+
+	v := validator.New()
+	for category, words := range keywords {
+		v.Check(category.IsValid(), string(category), fmt.Sprintf("unknown category %q", category))
+		for i, word := range words {
+			words[i] = normalizeDescription(word)
+			v.Check(words[i] != "", validator.Index(string(category), i), "keywords cannot be empty")
+		}
+	}
+	if !v.Ok() {
+		return Classifier{}, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid category dictionary",
+			Details: v.Errors,
+		}
+	}
+
+	return Classifier{keywords}, nil
+}
+
+// Classify returns the category of the description, CategoryOther when no
+// keyword matches.
+func (c Classifier) Classify(description string) Category {
+	normalized := " " + normalizeDescription(description) + " "
+	match := CategoryOther
+	matchLen := 0
+
+	for _, category := range categories {
+		for _, keyword := range c.keywords[category] {
+			if len(keyword) > matchLen && strings.Contains(normalized, " "+keyword+" ") {
+				match = category
+				matchLen = len(keyword)
+			}
+		}
+	}
+
+	return match
+}
+
+// normalizeDescription lowercases s and turns every run of characters other
+// than letters, digits and & into a single space.
+func normalizeDescription(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	}), " ")
+}
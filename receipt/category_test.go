@@ -0,0 +1,105 @@
+package receipt
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	classifier := DefaultClassifier()
+
+	tests := []struct {
+		description string
+		want        Category
+	}{
+		{"Mountain Dew 12PK", CategoryBeverages},
+		{"DiGiorno Pizza", CategoryFrozen},
+		{"Knorr Creamy Chicken", CategoryMeat},
+		{"Doritos Nacho Cheese", CategorySnacks},
+		{"   Klarbrunn 12-PK 12 FL OZ  ", CategoryOther},
+		{"ORANGE JUICE 52oz", CategoryBeverages},
+		{"Navel Oranges", CategoryProduce},
+		{"Pineapple", CategoryOther},
+		{"M&M Peanut", CategorySnacks},
+	}
+
+	for _, tt := range tests {
+		got := classifier.Classify(tt.description)
+		if got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.description, got, tt.want)
+		}
+	}
+}
+
+func TestParseClassifier(t *testing.T) {
+	classifier, err := ParseClassifier([]byte(`{"produce": ["Pine Apple"]}`))
+	if err != nil {
+		t.Fatalf("ParseClassifier() error = %v", err)
+	}
+	if got := classifier.Classify("pine-apple chunks"); got != CategoryProduce {
+		t.Errorf("expected %s. got %s", CategoryProduce, got)
+	}
+
+	for _, input := range []string{
+		`{"toys": ["lego"]}`,
+		`{"produce": ["  "]}`,
+		`["apple"]`,
+	} {
+		_, err := ParseClassifier([]byte(input))
+		if err == nil {
+			t.Errorf("ParseClassifier(%s) expected an error", input)
+		}
+	}
+}
+
+func TestSummarizeCategories(t *testing.T) {
+	items := []Item{
+		{ShortDescription: "Gatorade", Price: money("2.25"), Category: CategoryBeverages},
+		{ShortDescription: "Apples", Price: money("1.50"), Category: CategoryProduce},
+		{ShortDescription: "Gatorade", Price: money("2.25"), Category: CategoryBeverages},
+	}
+
+	got := SummarizeCategories(items)
+	want := []CategorySummary{
+		{Category: CategoryProduce, Items: 1, Total: money("1.50")},
+		{Category: CategoryBeverages, Items: 2, Total: money("4.50")},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d summaries. got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %+v. got %+v", want[i], got[i])
+		}
+	}
+}
+
+func TestItemCategoryRule(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`{
+		"version": "test",
+		"rules": [{"name": "drinks", "kind": "item_category", "points": 4, "params": {"category": "beverages"}}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseRuleset() error = %v", err)
+	}
+
+	rec := Receipt{
+		Items: []Item{
+			{ShortDescription: "Gatorade", Category: CategoryBeverages},
+			{ShortDescription: "Apples", Category: CategoryProduce},
+			{ShortDescription: "Gatorade", Category: CategoryBeverages},
+		},
+	}
+
+	if points := ruleset.Score(rec); points != 8 {
+		t.Errorf("expected 8 points. got %d", points)
+	}
+
+	_, err = ParseRuleset([]byte(`{
+		"version": "test",
+		"rules": [{"name": "toys", "kind": "item_category", "points": 4, "params": {"category": "toys"}}]
+	}`))
+	if err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
@@ -0,0 +1,29 @@
+package receipt
+
+import (
+	"strings"
+
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+// DeletionDTO is the request to soft delete a receipt, Reason is kept with
+// the receipt as evidence of why it was removed.
+type DeletionDTO struct {
+	Reason string `json:"reason"`
+}
+
+func (dto DeletionDTO) IsValid() (bool, map[string][]string) {
+	v := validator.New()
+
+	dto.ValidateReason(v)
+
+	return v.Ok(), v.Errors
+}
+
+func (dto DeletionDTO) ValidateReason(v *validator.Validator) {
+	const key = "reason"
+	const maxLen = 500
+
+	v.Check(strings.TrimSpace(dto.Reason) != "", key, "reason cannot be empty")
+	v.Check(len(dto.Reason) <= maxLen, key, "reason max length is 500 characters")
+}
@@ -0,0 +1,80 @@
+package receipt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestDelete(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repository := &batchRepository{}
+	service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	rec, err := service.Process(context.Background(), ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		reason string
+		code   string
+	}{
+		{"empty reason", "", errs.EINVALID},
+		{"blank reason", "   ", errs.EINVALID},
+		{"too long reason", strings.Repeat("a", 501), errs.EINVALID},
+		{"valid reason", " Fraudulent receipt ", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := service.Delete(context.Background(), rec.ID, DeletionDTO{Reason: test.reason})
+			if errs.ErrorCode(err) != test.code {
+				t.Errorf("expected error code %q. got %v", test.code, err)
+			}
+		})
+	}
+
+	if rec.DeletedAt == nil || rec.DeleteReason != "Fraudulent receipt" {
+		t.Errorf("expected the receipt to be deleted with the trimmed reason. got %v %q", rec.DeletedAt, rec.DeleteReason)
+	}
+
+	_, err = service.GetById(context.Background(), rec.ID, false)
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected deleted receipts not to be found. got %v", err)
+	}
+	_, err = service.GetPointsById(context.Background(), rec.ID, "", false)
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected the points of deleted receipts not to be found. got %v", err)
+	}
+	_, err = service.GetPointsBreakdownById(context.Background(), rec.ID, "", false)
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected the breakdown of deleted receipts not to be found. got %v", err)
+	}
+
+	deleted, err := service.GetById(context.Background(), rec.ID, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted.DeleteReason != "Fraudulent receipt" {
+		t.Errorf("expected the deleted receipt with its reason. got %q", deleted.DeleteReason)
+	}
+
+	_, err = service.Patch(context.Background(), rec.ID, map[string]any{"retailer": "Walmart"})
+	if errs.ErrorCode(err) != errs.ECONFLICT {
+		t.Errorf("expected deleted receipts not to be amended. got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package receipt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// DuplicatePolicy decides what happens to a receipt whose fingerprint matches
+// one already stored.
+type DuplicatePolicy string
+
+const (
+	// DuplicateReject refuses the receipt with a conflict error.
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateZero stores the receipt with zero points.
+	DuplicateZero DuplicatePolicy = "zero"
+	// DuplicateFlag stores and scores the receipt as usual, only marking it
+	// as a duplicate.
+	DuplicateFlag DuplicatePolicy = "flag"
+)
+
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	policy := DuplicatePolicy(s)
+	switch policy {
+	case DuplicateReject, DuplicateZero, DuplicateFlag:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("unknown duplicate policy %q, it should be reject, zero or flag", s)
+}
+
+// ErrOriginalExists is returned by ReceiptRepository when a receipt stored as
+// an original has the fingerprint of another original, stored by a concurrent
+// request after the first one was looked up.
+var ErrOriginalExists = errors.New("an original receipt with the fingerprint exists")
+
+// duplicateError reports that a receipt duplicates the receipt originalID.
+func duplicateError(originalID string) error {
+	return &errs.Error{
+		Code:    errs.ECONFLICT,
+		Message: "Duplicate receipt",
+		Details: map[string][]string{
+			"duplicateOf": {originalID},
+		},
+	}
+}
+
+// ContentFingerprint identifies the content of the receipt: its normalized retailer,
+// local purchase date and time, total and items, in any order. The timezone
+// and item categories are left out, a paper receipt scanned twice must match
+// however it was entered.
+func (r Receipt) ContentFingerprint() string {
+	items := make([]string, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = fmt.Sprintf("%s|%d", normalizeDescription(item.ShortDescription), item.Price.Cents())
+	}
+	slices.Sort(items)
+
+	h := sha256.New()
+	fmt.Fprintf(
+		h,
+		"%s\n%s\n%d\n%s",
+		normalizeDescription(r.Retailer),
+		r.PurchasedAt.Format("2006-01-02T15:04"),
+		r.Total.Cents(),
+		strings.Join(items, "\n"),
+	)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
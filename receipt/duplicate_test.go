@@ -0,0 +1,223 @@
+package receipt
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestContentFingerprint(t *testing.T) {
+	newYork, _ := LoadTimezone("America/New_York")
+
+	base := Receipt{
+		Retailer:    "M&M Corner Market",
+		PurchasedAt: time.Date(2022, time.March, 20, 14, 33, 0, 0, time.UTC),
+		Total:       money("4.50"),
+		Items: []Item{
+			{ShortDescription: "Gatorade", Price: money("2.25"), Category: CategoryBeverages},
+			{ShortDescription: "Doritos", Price: money("2.25"), Category: CategorySnacks},
+		},
+	}
+
+	same := base
+	same.Retailer = "  m&m corner   MARKET "
+	same.PurchasedAt = time.Date(2022, time.March, 20, 14, 33, 0, 0, newYork)
+	same.Items = []Item{
+		{ShortDescription: "doritos", Price: money("2.25")},
+		{ShortDescription: "GATORADE ", Price: money("2.25")},
+	}
+	if base.ContentFingerprint() != same.ContentFingerprint() {
+		t.Error("expected equal fingerprints for the same receipt entered differently")
+	}
+
+	tests := map[string]func(r *Receipt){
+		"retailer": func(r *Receipt) { r.Retailer = "Target" },
+		"time":     func(r *Receipt) { r.PurchasedAt = r.PurchasedAt.Add(time.Minute) },
+		"total":    func(r *Receipt) { r.Total = money("4.51") },
+		"price":    func(r *Receipt) { r.Items = []Item{base.Items[0], {ShortDescription: "Doritos", Price: money("2.26")}} },
+		"items":    func(r *Receipt) { r.Items = base.Items[:1] },
+	}
+	for name, change := range tests {
+		other := base
+		change(&other)
+		if base.ContentFingerprint() == other.ContentFingerprint() {
+			t.Errorf("expected a different fingerprint after changing the %s", name)
+		}
+	}
+}
+
+func TestProcessDuplicatePolicy(t *testing.T) {
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		policy         DuplicatePolicy
+		expectedErr    string
+		expectedPoints int
+	}{
+		{DuplicateReject, errs.ECONFLICT, 0},
+		{DuplicateZero, "", 0},
+		{DuplicateFlag, "", 31},
+	}
+
+	for _, tt := range tests {
+		repository := &batchRepository{}
+		service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), tt.policy, DefaultValidationPolicy())
+
+		original, err := service.Process(context.Background(), dto)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.policy, err)
+		}
+
+		duplicate, err := service.Process(context.Background(), dto)
+		if errs.ErrorCode(err) != tt.expectedErr && !(err == nil && tt.expectedErr == "") {
+			t.Errorf("%s: expected error code %q. got %v", tt.policy, tt.expectedErr, err)
+		}
+
+		if tt.expectedErr != "" {
+			if !slices.Equal(errs.ErrorDetails(err)["duplicateOf"], []string{original.ID}) {
+				t.Errorf("%s: expected duplicateOf %s. got %v", tt.policy, original.ID, errs.ErrorDetails(err))
+			}
+			if len(repository.created) != 1 {
+				t.Errorf("%s: expected the duplicate not to be stored", tt.policy)
+			}
+			continue
+		}
+
+		if duplicate.DuplicateOf != original.ID {
+			t.Errorf("%s: expected duplicateOf %s. got %q", tt.policy, original.ID, duplicate.DuplicateOf)
+		}
+		if *duplicate.Points != tt.expectedPoints {
+			t.Errorf("%s: expected %d points. got %d", tt.policy, tt.expectedPoints, *duplicate.Points)
+		}
+	}
+}
+
+func TestProcessBatchDuplicates(t *testing.T) {
+	repository := &batchRepository{}
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+
+	results, err := service.ProcessBatch(context.Background(), []ReceiptDTO{dto, dto})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(results[1].Errors["duplicateOf"], []string{results[0].ID}) {
+		t.Errorf("expected the second receipt to duplicate %s. got %+v", results[0].ID, results[1])
+	}
+	if len(repository.created) != 1 {
+		t.Errorf("expected 1 stored receipt. got %d", len(repository.created))
+	}
+}
+
+// racingRepository stores its receipts like the unique index on the
+// fingerprints of originals, and misses the first lookup as if the original
+// was stored by a concurrent request right after it.
+type racingRepository struct {
+	batchRepository
+	lookups int
+}
+
+func (r *racingRepository) FindIdByFingerprint(ctx context.Context, fingerprint string) (string, error) {
+	r.lookups++
+	if r.lookups == 1 {
+		return "", &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	return r.batchRepository.FindIdByFingerprint(ctx, fingerprint)
+}
+
+func (r *racingRepository) Create(ctx context.Context, receipt *Receipt) error {
+	return r.CreateBatch(ctx, []*Receipt{receipt})
+}
+
+func (r *racingRepository) CreateBatch(ctx context.Context, receipts []*Receipt) error {
+	for _, rec := range receipts {
+		_, err := r.batchRepository.FindIdByFingerprint(ctx, rec.Fingerprint)
+		if rec.DuplicateOf == "" && err == nil {
+			return ErrOriginalExists
+		}
+	}
+
+	return r.batchRepository.CreateBatch(ctx, receipts)
+}
+
+func TestProcessConcurrentDuplicate(t *testing.T) {
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	original, err := newReceipt(dto, DefaultValidationPolicy(), DefaultClassifier())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		policy      DuplicatePolicy
+		batch       bool
+		expectedErr string
+	}{
+		{DuplicateReject, false, errs.ECONFLICT},
+		{DuplicateFlag, false, ""},
+		{DuplicateReject, true, ""},
+		{DuplicateZero, true, ""},
+	}
+
+	for _, tt := range tests {
+		repository := &racingRepository{batchRepository: batchRepository{created: []*Receipt{original}}}
+		service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), tt.policy, DefaultValidationPolicy())
+
+		if tt.batch {
+			results, err := service.ProcessBatch(context.Background(), []ReceiptDTO{dto})
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.policy, err)
+			}
+			if tt.policy == DuplicateReject && !slices.Equal(results[0].Errors["duplicateOf"], []string{original.ID}) {
+				t.Errorf("%s: expected the receipt to duplicate %s. got %+v", tt.policy, original.ID, results[0])
+			}
+		} else {
+			_, err := service.Process(context.Background(), dto)
+			if errs.ErrorCode(err) != tt.expectedErr && !(err == nil && tt.expectedErr == "") {
+				t.Errorf("%s: expected error code %q. got %v", tt.policy, tt.expectedErr, err)
+			}
+		}
+
+		for _, rec := range repository.created[1:] {
+			if rec.DuplicateOf != original.ID {
+				t.Errorf("%s: expected the stored receipt to duplicate %s. got %q", tt.policy, original.ID, rec.DuplicateOf)
+			}
+		}
+	}
+}
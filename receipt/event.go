@@ -0,0 +1,60 @@
+package receipt
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventReceiptProcessed EventType = "receipt.processed"
+	EventReceiptUpdated   EventType = "receipt.updated"
+	EventReceiptDeleted   EventType = "receipt.deleted"
+	EventReceiptPurged    EventType = "receipt.purged"
+)
+
+// EventTypes are the receipt events, in the order they are documented.
+var EventTypes = []EventType{
+	EventReceiptProcessed,
+	EventReceiptUpdated,
+	EventReceiptDeleted,
+	EventReceiptPurged,
+}
+
+func (t EventType) IsValid() bool {
+	for _, eventType := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event records a change to a receipt. Repositories store it in an outbox
+// along with the change, so it is only published if the change is stored.
+// Restoring a deleted receipt is an update, so is pointing the duplicates of
+// a purged receipt at another original.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      EventData `json:"data"`
+}
+
+type EventData struct {
+	// Receipt is the receipt as it was right after the change, or right
+	// before it for a purge.
+	Receipt *Receipt `json:"receipt"`
+}
+
+func NewEvent(eventType EventType, receipt *Receipt, at time.Time) Event {
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: at,
+		Data:      EventData{Receipt: receipt},
+	}
+}
@@ -1,6 +1,7 @@
 package receipt
 
 import (
+	"fmt"
 	"math"
 	"slices"
 	"strings"
@@ -8,16 +9,14 @@ import (
 	"github.com/gmr458/receipt-processor/validator"
 )
 
-const (
-	MAX_PAGE  = 10_000_000
-	MAX_LIMIT = 100
-)
-
 type Filters struct {
 	Page         int
 	Limit        int
 	Sort         string
 	SortSafeList []string
+
+	// IncludeDeleted lists the soft deleted receipts too.
+	IncludeDeleted bool
 }
 
 func NewFilters(sortSafeList ...string) Filters {
@@ -26,13 +25,13 @@ func NewFilters(sortSafeList ...string) Filters {
 	}
 }
 
-func (f Filters) IsValid() (bool, map[string]string) {
+func (f Filters) IsValid(policy ValidationPolicy) (bool, map[string][]string) {
 	v := validator.New()
 
 	v.Check(f.Page > 0, "page", "must be greater than zero")
-	v.Check(f.Page <= MAX_PAGE, "page", "must be a maximum if 10 million")
+	v.Check(f.Page <= policy.MaxPage, "page", fmt.Sprintf("must be a maximum of %d", policy.MaxPage))
 	v.Check(f.Limit > 0, "limit", "must be greater than zero")
-	v.Check(f.Limit <= MAX_LIMIT, "limit", "must be a maximum if 100")
+	v.Check(f.Limit <= policy.MaxLimit, "limit", fmt.Sprintf("must be a maximum of %d", policy.MaxLimit))
 	v.Check(slices.Contains(f.SortSafeList, f.Sort), "sort", "invalid sort value")
 
 	return v.Ok(), v.Errors
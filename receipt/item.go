@@ -1,8 +1,19 @@
 package receipt
 
+// Item is a receipt line. Price is the line total, Quantity units of
+// UnitPrice each.
 type Item struct {
-	ID               string  `json:"id"`
-	ShortDescription string  `json:"shortDescription"`
-	Price            float64 `json:"price"`
-	ReceiptID        string  `json:"receiptID"`
+	ID               string   `json:"id"`
+	ShortDescription string   `json:"shortDescription"`
+	Price            Money    `json:"price"`
+	Quantity         int      `json:"quantity"`
+	UnitPrice        Money    `json:"unitPrice"`
+	Category         Category `json:"category"`
+	ReceiptID        string   `json:"receiptID"`
+}
+
+// units returns the quantity of the item, items stored before quantities
+// existed count as one unit.
+func (item Item) units() int {
+	return max(item.Quantity, 1)
 }
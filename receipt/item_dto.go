@@ -1,6 +1,61 @@
 package receipt
 
+import (
+	"fmt"
+
+	"github.com/gmr458/receipt-processor/validator"
+)
+
 type ItemDTO struct {
-	ShortDescription string  `json:"shortDescription"`
-	Price            float64 `json:"price"`
+	ShortDescription string `json:"shortDescription"`
+	Price            Money  `json:"price"`
+
+	// Quantity and UnitPrice are optional, a line of several units must
+	// have both and Price must be Quantity × UnitPrice. A missing Quantity
+	// means one unit.
+	Quantity  int   `json:"quantity,omitempty"`
+	UnitPrice Money `json:"unitPrice,omitempty"`
+
+	// Category is optional, items without one are classified by their
+	// ShortDescription.
+	Category Category `json:"category,omitempty"`
+}
+
+// validate checks the item at path, the errors of each field are reported
+// under its own path.
+func (dto ItemDTO) validate(v *validator.Validator, path string, policy ValidationPolicy) {
+	shortDescription := validator.Field(path, "shortDescription")
+	v.Check(dto.ShortDescription != "", shortDescription, "shortDescription cannot be empty")
+	v.Check(
+		len(dto.ShortDescription) <= policy.DescriptionMaxLength,
+		shortDescription,
+		fmt.Sprintf("shortDescription max length is %d characters", policy.DescriptionMaxLength),
+	)
+
+	price := validator.Field(path, "price")
+	v.Check(dto.Price > 0, price, "price must be greater than 0.00")
+
+	v.Check(dto.Quantity >= 0, validator.Field(path, "quantity"), "quantity cannot be negative")
+
+	unitPrice := validator.Field(path, "unitPrice")
+	v.Check(dto.UnitPrice >= 0, unitPrice, "unitPrice cannot be negative")
+	v.Check(
+		dto.Quantity <= 1 || dto.UnitPrice != 0,
+		unitPrice,
+		"unitPrice is required when quantity is greater than 1",
+	)
+
+	quantity := max(dto.Quantity, 1)
+	lineTotal := dto.UnitPrice * Money(quantity)
+	v.Check(
+		dto.UnitPrice == 0 || dto.Price == lineTotal,
+		price,
+		fmt.Sprintf("price must be quantity times unitPrice, %d x %s = %s", quantity, dto.UnitPrice, lineTotal),
+	)
+
+	v.Check(
+		dto.Category == "" || dto.Category.IsValid(),
+		validator.Field(path, "category"),
+		fmt.Sprintf("unknown category %q, valid categories are %s", dto.Category, categoryNames()),
+	)
 }
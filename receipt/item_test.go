@@ -0,0 +1,96 @@
+package receipt
+
+import "testing"
+
+func TestValidateItemQuantity(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    ItemDTO
+		isValid bool
+	}{
+		{"single unit", ItemDTO{ShortDescription: "Gatorade", Price: money("2.25")}, true},
+		{"unit price of a single unit", ItemDTO{ShortDescription: "Gatorade", Price: money("2.25"), UnitPrice: money("2.25")}, true},
+		{"several units", ItemDTO{ShortDescription: "Gatorade", Price: money("6.75"), Quantity: 3, UnitPrice: money("2.25")}, true},
+		{"price not matching", ItemDTO{ShortDescription: "Gatorade", Price: money("6.00"), Quantity: 3, UnitPrice: money("2.25")}, false},
+		{"quantity without unit price", ItemDTO{ShortDescription: "Gatorade", Price: money("6.75"), Quantity: 3}, false},
+		{"negative quantity", ItemDTO{ShortDescription: "Gatorade", Price: money("2.25"), Quantity: -1}, false},
+	}
+
+	for _, tt := range tests {
+		dto := ReceiptDTO{
+			Retailer:     "Target",
+			PurchaseDate: "2022-01-02",
+			PurchaseTime: "13:13",
+			Total:        tt.item.Price,
+			Items:        []ItemDTO{tt.item},
+		}
+
+		isValid, errors := dto.IsValid(DefaultValidationPolicy())
+		if isValid != tt.isValid {
+			t.Errorf("%s: expected valid to be %t. got %t %v", tt.name, tt.isValid, isValid, errors)
+		}
+	}
+}
+
+func TestItemCountRules(t *testing.T) {
+	r := Receipt{
+		Items: []Item{
+			{ShortDescription: "Gatorade", Price: money("6.75"), Quantity: 3, UnitPrice: money("2.25"), Category: CategoryBeverages},
+			{ShortDescription: "Doritos", Price: money("2.25"), Quantity: 1, UnitPrice: money("2.25"), Category: CategorySnacks},
+		},
+	}
+
+	tests := []struct {
+		rule     Rule
+		expected int
+	}{
+		{Rule{Kind: RuleEveryNItems, Points: 5, Params: RuleParams{Every: 2}}, 5},
+		{Rule{Kind: RuleEveryNItems, Points: 5, Params: RuleParams{Every: 2, Count: CountLines}}, 5},
+		{Rule{Kind: RuleEveryNItems, Points: 5, Params: RuleParams{Every: 2, Count: CountUnits}}, 10},
+		{Rule{Kind: RuleItemCategory, Points: 3, Params: RuleParams{Category: CategoryBeverages}}, 3},
+		{Rule{Kind: RuleItemCategory, Points: 3, Params: RuleParams{Category: CategoryBeverages, Count: CountUnits}}, 9},
+	}
+
+	for _, tt := range tests {
+		got := tt.rule.Apply(r)
+		if got != tt.expected {
+			t.Errorf("%s counting %s: expected %d points. got %d", tt.rule.Kind, tt.rule.Params.Count.label(), tt.expected, got)
+		}
+	}
+}
+
+func TestValidateItemPaths(t *testing.T) {
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("9.00"),
+		Items: []ItemDTO{
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+			{ShortDescription: "", Price: money("0.00")},
+			{ShortDescription: "Doritos", Price: money("-6.75"), Quantity: 3, UnitPrice: money("2.25"), Category: "toys"},
+		},
+	}
+
+	_, errors := dto.IsValid(DefaultValidationPolicy())
+
+	tests := []struct {
+		key    string
+		errors int
+	}{
+		{"items[1].shortDescription", 1},
+		{"items[1].price", 1},
+		{"items[2].price", 2},
+		{"items[2].category", 1},
+		{"total", 1},
+	}
+
+	for _, tt := range tests {
+		if len(errors[tt.key]) != tt.errors {
+			t.Errorf("expected %d errors for %s. got %q", tt.errors, tt.key, errors[tt.key])
+		}
+	}
+	if len(errors) != len(tests) {
+		t.Errorf("expected errors for %d fields. got %v", len(tests), errors)
+	}
+}
@@ -0,0 +1,48 @@
+package receipt
+
+import (
+	"context"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is a receipt submitted for asynchronous processing. Input is kept until
+// a worker processes it, the job then records either the created receipt or
+// the error it failed with, shaped like an error response.
+type Job struct {
+	ID        string              `json:"id"`
+	Status    JobStatus           `json:"status"`
+	Input     ReceiptDTO          `json:"-"`
+	ReceiptID string              `json:"receiptID,omitempty"`
+	Points    *int                `json:"points,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Details   map[string][]string `json:"details,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+type JobRepository interface {
+	FindById(ctx context.Context, id string) (*Job, error)
+	Create(ctx context.Context, job *Job) error
+
+	// Claim marks the oldest queued job as running and returns it, or
+	// ENOTFOUND if none is queued. Concurrent callers never claim the same
+	// job.
+	Claim(ctx context.Context) (*Job, error)
+
+	// Finish stores the outcome of a running job, or puts it back in the
+	// queue if its status is queued.
+	Finish(ctx context.Context, job *Job) error
+
+	// Requeue puts every running job back in the queue and returns how many
+	// there were.
+	Requeue(ctx context.Context) (int, error)
+}
@@ -0,0 +1,104 @@
+package receipt
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+type JobService struct {
+	repository JobRepository
+	receipts   Service
+}
+
+func NewJobService(repository JobRepository, receipts Service) JobService {
+	return JobService{
+		repository,
+		receipts,
+	}
+}
+
+// Submit queues dto for processing. The receipt is validated by the worker
+// that runs the job, invalid receipts end up as failed jobs.
+func (s *JobService) Submit(ctx context.Context, dto ReceiptDTO) (*Job, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	job := &Job{
+		ID:        uuid.New().String(),
+		Status:    JobQueued,
+		Input:     dto,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.repository.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *JobService) GetById(ctx context.Context, id string) (*Job, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Job not found"}
+	}
+
+	return s.repository.FindById(ctx, id)
+}
+
+// RunNext claims the oldest queued job and processes its receipt, it returns
+// nil if no job is queued. An invalid or conflicting receipt fails the job,
+// any other error puts the job back in the queue and is returned along with
+// it.
+//
+// The receipt takes the ID of the job. A job requeued after a crash between
+// storing its receipt and finishing finds that receipt instead of processing
+// it again.
+func (s *JobService) RunNext(ctx context.Context) (*Job, error) {
+	job, err := s.repository.Claim(ctx)
+	if err != nil {
+		if errs.ErrorCode(err) == errs.ENOTFOUND {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rec, processErr := s.receipts.repository.FindById(ctx, job.ID)
+	if errs.ErrorCode(processErr) == errs.ENOTFOUND {
+		rec, processErr = s.receipts.processAs(ctx, job.ID, job.Input)
+	}
+	switch errs.ErrorCode(processErr) {
+	case "":
+		job.Status = JobSucceeded
+		job.ReceiptID = rec.ID
+		job.Points = rec.Points
+	case errs.EINVALID, errs.ECONFLICT:
+		job.Status = JobFailed
+		job.Error = errs.ErrorMessage(processErr)
+		job.Details = errs.ErrorDetails(processErr)
+	default:
+		job.Status = JobQueued
+	}
+	job.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	err = s.repository.Finish(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == JobQueued {
+		return job, processErr
+	}
+
+	return job, nil
+}
+
+// Requeue puts back in the queue the jobs left running by a previous process,
+// it must be called before any worker starts.
+func (s *JobService) Requeue(ctx context.Context) (int, error) {
+	return s.repository.Requeue(ctx)
+}
@@ -0,0 +1,196 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// memoryJobs is a queue of jobs kept in submission order.
+type memoryJobs struct {
+	JobRepository
+	jobs []*Job
+}
+
+func (r *memoryJobs) Create(ctx context.Context, job *Job) error {
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+func (r *memoryJobs) Claim(ctx context.Context) (*Job, error) {
+	for _, job := range r.jobs {
+		if job.Status == JobQueued {
+			job.Status = JobRunning
+			return job, nil
+		}
+	}
+
+	return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "No queued job"}
+}
+
+func (r *memoryJobs) Finish(ctx context.Context, job *Job) error {
+	return nil
+}
+
+func TestRunNext(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receipts := &batchRepository{}
+	service := NewJobService(
+		&memoryJobs{},
+		NewService(receipts, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy()),
+	)
+
+	valid := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	invalid := valid
+	invalid.Retailer = ""
+
+	submitted := []*Job{}
+	for _, dto := range []ReceiptDTO{valid, invalid} {
+		job, err := service.Submit(context.Background(), dto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if job.Status != JobQueued {
+			t.Errorf("expected status %s. got %s", JobQueued, job.Status)
+		}
+		submitted = append(submitted, job)
+	}
+
+	job, err := service.RunNext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != submitted[0].ID || job.Status != JobSucceeded {
+		t.Errorf("expected job %s to succeed. got %s %s", submitted[0].ID, job.ID, job.Status)
+	}
+	if len(receipts.created) != 1 || job.ReceiptID != receipts.created[0].ID {
+		t.Errorf("expected the job to record the stored receipt. got %q", job.ReceiptID)
+	}
+	if job.Points == nil || *job.Points != 31 {
+		t.Errorf("expected 31 points. got %v", job.Points)
+	}
+
+	job, err = service.RunNext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != submitted[1].ID || job.Status != JobFailed {
+		t.Errorf("expected job %s to fail. got %s %s", submitted[1].ID, job.ID, job.Status)
+	}
+	if len(job.Details["retailer"]) == 0 {
+		t.Errorf("expected a retailer error. got %v", job.Details)
+	}
+
+	job, err = service.RunNext(context.Background())
+	if err != nil || job != nil {
+		t.Errorf("expected no job once the queue is empty. got %v, %v", job, err)
+	}
+}
+
+func TestRunNextAfterCrash(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receipts := &batchRepository{}
+	service := NewJobService(
+		&memoryJobs{},
+		NewService(receipts, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy()),
+	)
+
+	job, err := service.Submit(context.Background(), ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first run stored the receipt and crashed before finishing the job,
+	// which was then requeued.
+	_, err = service.RunNext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job.Status = JobQueued
+	job.ReceiptID = ""
+
+	job, err = service.RunNext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != JobSucceeded || job.ReceiptID != job.ID {
+		t.Errorf("expected the job to succeed with receipt %s. got %s %q, %q", job.ID, job.Status, job.ReceiptID, job.Error)
+	}
+	if len(receipts.created) != 1 {
+		t.Errorf("expected the receipt to be stored once. got %d", len(receipts.created))
+	}
+}
+
+// failingRepository fails to store receipts until it is fixed.
+type failingRepository struct {
+	*batchRepository
+	broken bool
+}
+
+func (r *failingRepository) Create(ctx context.Context, receipt *Receipt) error {
+	if r.broken {
+		return errors.New("database is locked")
+	}
+
+	return r.batchRepository.Create(ctx, receipt)
+}
+
+func TestRunNextRequeuesOnInternalError(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receipts := &failingRepository{batchRepository: &batchRepository{}, broken: true}
+	service := NewJobService(
+		&memoryJobs{},
+		NewService(receipts, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy()),
+	)
+
+	submitted, err := service.Submit(context.Background(), ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:13",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	job, err := service.RunNext(context.Background())
+	if err == nil {
+		t.Fatalf("expected the internal error to be returned")
+	}
+	if job == nil || job.ID != submitted.ID || job.Status != JobQueued || job.Error != "" {
+		t.Fatalf("expected job %s to be queued again. got %+v", submitted.ID, job)
+	}
+
+	receipts.broken = false
+	job, err = service.RunNext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != submitted.ID || job.Status != JobSucceeded {
+		t.Errorf("expected job %s to succeed once retried. got %s %s", submitted.ID, job.ID, job.Status)
+	}
+}
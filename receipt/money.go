@@ -0,0 +1,165 @@
+package receipt
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Money is an exact amount of money in cents. It is encoded in JSON as a
+// decimal number with two decimal places and stored in SQLite as an INTEGER
+// number of cents.
+type Money int64
+
+const maxMoneyDigits = 15
+
+type InvalidMoneyError struct {
+	Value  string
+	Reason string
+}
+
+func (e *InvalidMoneyError) Error() string {
+	return fmt.Sprintf("invalid money amount %q: %s", e.Value, e.Reason)
+}
+
+// ParseMoney parses a decimal amount such as "35.35", "9" or "-0.5". More
+// than two decimal places is an *InvalidMoneyError rather than a silent
+// rounding.
+func ParseMoney(s string) (Money, error) {
+	str := s
+	negative := strings.HasPrefix(str, "-")
+	if negative {
+		str = str[1:]
+	}
+
+	units, cents, hasPoint := strings.Cut(str, ".")
+	if units == "" || (hasPoint && cents == "") {
+		return 0, &InvalidMoneyError{Value: s, Reason: "not a decimal number"}
+	}
+	if len(cents) > 2 {
+		return 0, &InvalidMoneyError{Value: s, Reason: "more than two decimal places"}
+	}
+	if len(units) > maxMoneyDigits {
+		return 0, &InvalidMoneyError{Value: s, Reason: "too large"}
+	}
+
+	digits := units + cents + strings.Repeat("0", 2-len(cents))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, &InvalidMoneyError{Value: s, Reason: "not a decimal number"}
+		}
+	}
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, &InvalidMoneyError{Value: s, Reason: "not a decimal number"}
+	}
+	if negative {
+		n = -n
+	}
+
+	return Money(n), nil
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+func (m Money) String() string {
+	n := int64(m)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// MulCeil multiplies the amount by factor and rounds the result up to a whole
+// currency unit.
+func (m Money) MulCeil(factor float64) int {
+	return mulCeil(int64(m), factor, 100)
+}
+
+// mulCeil returns ceil(n * factor / divisor). The factor is taken at its
+// shortest decimal representation, so 0.2 means exactly one fifth and no
+// floating point error leaks into the result.
+func mulCeil(n int64, factor float64, divisor int64) int {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		panic(fmt.Sprintf("invalid factor: %v", factor))
+	}
+
+	x := new(big.Rat).SetInt64(n)
+	x.Mul(x, f)
+	x.Quo(x, big.NewRat(divisor, 1))
+
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+
+	return int(q.Int64())
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding a decimal amount.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// UnmarshalYAML accepts a YAML number or a string holding a decimal amount.
+func (m *Money) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("cannot unmarshal a YAML %s into Money", value.ShortTag())
+	}
+
+	parsed, err := ParseMoney(value.Value)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package receipt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{"35.35", 3535, false},
+		{"9", 900, false},
+		{"9.5", 950, false},
+		{"0.01", 1, false},
+		{"-1.25", -125, false},
+		{"1.005", 0, true},
+		{"1.", 0, true},
+		{".5", 0, true},
+		{"1e2", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		input Money
+		want  string
+	}{
+		{3535, "35.35"},
+		{900, "9.00"},
+		{1, "0.01"},
+		{-125, "-1.25"},
+		{0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulCeil(t *testing.T) {
+	tests := []struct {
+		input  Money
+		factor float64
+		want   int
+	}{
+		{1225, 0.2, 3},
+		{500, 0.2, 1},
+		{1000, 0.2, 2},
+		{1001, 0.2, 3},
+		{0, 0.2, 0},
+		{333, 0.3, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.MulCeil(tt.factor); got != tt.want {
+			t.Errorf("Money(%d).MulCeil(%v) = %d, want %d", tt.input, tt.factor, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var dto ItemDTO
+	err := json.Unmarshal([]byte(`{"shortDescription": "Gatorade", "price": 2.25}`), &dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Price != 225 {
+		t.Errorf("expected price to be 225 cents. got %d", dto.Price)
+	}
+
+	err = json.Unmarshal([]byte(`{"shortDescription": "Gatorade", "price": "2.25"}`), &dto)
+	if err != nil || dto.Price != 225 {
+		t.Errorf("expected string price to decode to 225 cents. got %d, %v", dto.Price, err)
+	}
+
+	b, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"shortDescription":"Gatorade","price":2.25}`; string(b) != want {
+		t.Errorf("expected %s. got %s", want, b)
+	}
+
+	err = json.Unmarshal([]byte(`{"shortDescription": "Gatorade", "price": 2.255}`), &dto)
+	var moneyErr *InvalidMoneyError
+	if !errors.As(err, &moneyErr) || moneyErr.Value != "2.255" {
+		t.Errorf("expected an invalid money error for 2.255. got %v", err)
+	}
+}
@@ -2,115 +2,238 @@ package receipt
 
 import (
 	"context"
-	"math"
+	"encoding/json"
 	"strings"
 	"time"
 )
 
 type Receipt struct {
-	ID           string    `json:"id"`
-	Retailer     string    `json:"retailer"`
-	PurchaseDate time.Time `json:"purchaseDate"`
-	PurchaseTime time.Time `json:"purchaseTime"`
-	Total        float64   `json:"total"`
-	Items        []Item    `json:"items"`
+	ID       string `json:"id"`
+	Retailer string `json:"retailer"`
+
+	// PurchasedAt is the purchase instant in the receipt's timezone, so its
+	// date and clock are the local wall-clock time of the purchase.
+	PurchasedAt time.Time `json:"purchasedAt"`
+	Timezone    string    `json:"timezone"`
+
+	Total Money  `json:"total"`
+	Items []Item `json:"items"`
+
+	// Adjustments are the tax, discount, tip and fee lines, Total is the
+	// Subtotal of the items plus the adjustments.
+	Adjustments []Adjustment `json:"adjustments"`
+
+	// Categories summarizes Items by category.
+	Categories []CategorySummary `json:"categories"`
+
+	// Points is the score recorded when the receipt was created, nil for
+	// receipts created before scores were recorded. It includes the points
+	// added by Campaigns.
+	Points         *int              `json:"points"`
+	RulesetVersion string            `json:"rulesetVersion"`
+	Campaigns      []AppliedCampaign `json:"campaigns"`
+
+	// Fingerprint is the ContentFingerprint computed when the receipt was
+	// created. DuplicateOf is the ID of the receipt with the same fingerprint
+	// stored first, empty unless the receipt is a duplicate.
+	Fingerprint string `json:"-"`
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// DeletedAt is when the receipt was soft deleted and DeleteReason why,
+	// DeletedAt is nil unless the receipt is deleted.
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+	DeleteReason string     `json:"deleteReason,omitempty"`
+}
+
+// MarshalJSON adds the purchaseDate and purchaseTime fields receipts had
+// before PurchasedAt, both in the receipt's timezone.
+func (r Receipt) MarshalJSON() ([]byte, error) {
+	type receipt Receipt
+	return json.Marshal(struct {
+		receipt
+		PurchaseDate string `json:"purchaseDate"`
+		PurchaseTime string `json:"purchaseTime"`
+	}{
+		receipt:      receipt(r),
+		PurchaseDate: r.PurchasedAt.Format("2006-01-02"),
+		PurchaseTime: r.PurchasedAt.Format("15:04"),
+	})
+}
+
+// Score is a receipt's points and the ruleset version that produced them.
+type Score struct {
+	Points         int    `json:"points"`
+	RulesetVersion string `json:"rulesetVersion"`
 }
 
 type ReceiptRepository interface {
 	Find(ctx context.Context, filters Filters) (PaginatedReceipts, error)
 	FindById(ctx context.Context, id string) (*Receipt, error)
+	FindIdByFingerprint(ctx context.Context, fingerprint string) (string, error)
 	Create(ctx context.Context, receipt *Receipt) error
+	CreateBatch(ctx context.Context, receipts []*Receipt) error
+	Update(ctx context.Context, receipt *Receipt) error
+	FindRevisions(ctx context.Context, id string) ([]Revision, error)
+	Delete(ctx context.Context, id, reason string, at time.Time) error
+	Restore(ctx context.Context, id string, at time.Time) error
+	Purge(ctx context.Context, id string, at time.Time) ([]Attachment, error)
+	Each(ctx context.Context, fn func(*Receipt) error) error
 }
 
 type ReceiptCache interface {
 	SetPaginatedReceipts(ctx context.Context, key string, paginatedReceipts PaginatedReceipts, exp time.Duration) error
 	GetPaginatedReceipts(ctx context.Context, key string) (PaginatedReceipts, error)
-	GetPointsById(ctx context.Context, id string) (int, error)
-	SetPointsById(ctx context.Context, id string, points int, exp time.Duration) error
+	GetScoreById(ctx context.Context, id string) (Score, error)
+	SetScoreById(ctx context.Context, id string, score Score, exp time.Duration) error
+	GetReceiptById(ctx context.Context, id string) (*Receipt, error)
+	SetReceiptById(ctx context.Context, receipt *Receipt, exp time.Duration) error
+	DeleteReceiptById(ctx context.Context, id string) error
+	DeletePaginatedReceipts(ctx context.Context) error
 }
 
+// PaginatedReceiptsKeyPrefix starts the cache keys of the receipt list pages.
+const PaginatedReceiptsKeyPrefix = "receipts:page:"
+
 type PaginatedReceipts struct {
 	Receipts []Receipt `json:"receipts"`
 	Metadata *Metadata `json:"metadata"`
 }
 
 func (r Receipt) GetPointsRetailerName() int {
+	return r.pointsRetailerName(1)
+}
+
+func (r Receipt) GetPointsRoundDollar() int {
+	return r.pointsRoundDollar(BasisTotal, 50)
+}
+
+func (r Receipt) GetPointsTotalIsMultipleOf(multiple Money) int {
+	return r.pointsTotalIsMultipleOf(BasisTotal, multiple, 25)
+}
+
+func (r Receipt) GetPointsForEveryNItems(n int) int {
+	return r.pointsForEveryNItems(CountLines, n, 5)
+}
+
+func (r Receipt) GetPointsItemsDescription() int {
+	return r.pointsItemsDescription(3, 0.2)
+}
+
+func (r Receipt) GetPointsPurchaseDayIsOdd() int {
+	return r.pointsPurchaseDayIsOdd(6)
+}
+
+func (r Receipt) GetPointsTimeOfPurchase() int {
+	return r.pointsTimeOfPurchase(14*60, 16*60, 10)
+}
+
+// CalculateTotalPoints scores the receipt with the built-in default ruleset.
+func (r *Receipt) CalculateTotalPoints() int {
+	return DefaultRuleset().Score(*r)
+}
+
+func (r Receipt) pointsRetailerName(perChar int) int {
 	points := 0
 
 	for _, char := range r.Retailer {
 		if isAlphanumeric(char) {
-			points += 1
+			points += perChar
 		}
 	}
 
 	return points
 }
 
-func (r Receipt) GetPointsRoundDollar() int {
-	if hasZeroDecimal(r.Total) {
-		return 50
+func (r Receipt) pointsRoundDollar(basis Basis, points int) int {
+	if hasZeroDecimal(r.amount(basis)) {
+		return points
 	}
 
 	return 0
 }
 
-func (r Receipt) GetPointsTotalIsMultipleOf(f float64) int {
-	if xIsMultipleOfy(r.Total, f) {
-		return 25
+func (r Receipt) pointsTotalIsMultipleOf(basis Basis, multiple Money, points int) int {
+	if xIsMultipleOfy(r.amount(basis).Cents(), multiple.Cents()) {
+		return points
 	}
 
 	return 0
 }
 
-func (r Receipt) GetPointsForEveryNItems(n int) int {
-	points := 5
-	return (len(r.Items) / n) * points
+func (r Receipt) pointsForEveryNItems(count ItemCount, n, points int) int {
+	return (r.itemCount(count) / n) * points
 }
 
-func (r Receipt) GetPointsItemsDescription() int {
+// itemCount returns the number of item lines, or of units if count is
+// CountUnits.
+func (r Receipt) itemCount(count ItemCount) int {
+	if count != CountUnits {
+		return len(r.Items)
+	}
+
+	units := 0
+	for _, item := range r.Items {
+		units += item.units()
+	}
+
+	return units
+}
+
+func (r Receipt) pointsItemsDescription(lengthMultiple int, priceMultiplier float64) int {
 	points := 0
 
 	for _, item := range r.Items {
 		trimmedLen := len(strings.TrimSpace(item.ShortDescription))
-		if xIsMultipleOfy(float64(trimmedLen), 3.0) {
-			p := int(math.Ceil(item.Price * 0.2))
-			points += p
+		if xIsMultipleOfy(int64(trimmedLen), int64(lengthMultiple)) {
+			points += item.Price.MulCeil(priceMultiplier)
 		}
 	}
 
 	return points
 }
 
-func (r Receipt) GetPointsPurchaseDayIsOdd() int {
-	day := r.PurchaseDate.Day()
+func (r Receipt) pointsPurchaseDayIsOdd(points int) int {
+	day := r.PurchasedAt.Day()
 	if isOdd(day) {
-		return 6
+		return points
 	}
 
 	return 0
 }
 
-func (r Receipt) GetPointsTimeOfPurchase() int {
-	hours, mins, _ := r.PurchaseTime.Clock()
-	if hours == 14 && mins > 0 {
-		return 10
-	}
-	if hours == 15 {
-		return 10
+// pointsTimeOfPurchase awards points when the purchase time falls strictly
+// between start and end, both expressed in minutes since midnight.
+func (r Receipt) pointsTimeOfPurchase(start, end, points int) int {
+	hours, mins, _ := r.PurchasedAt.Clock()
+	minute := hours*60 + mins
+	if minute > start && minute < end {
+		return points
 	}
 	return 0
 }
 
-func (r *Receipt) CalculateTotalPoints() int {
-	points := 0
+// pointsItemCategory awards points for every item in category, or for every
+// unit if count is CountUnits.
+func (r Receipt) pointsItemCategory(count ItemCount, category Category, points int) int {
+	return r.categoryCount(count, category) * points
+}
 
-	points += r.GetPointsRetailerName()
-	points += r.GetPointsRoundDollar()
-	points += r.GetPointsTotalIsMultipleOf(0.25)
-	points += r.GetPointsForEveryNItems(2)
-	points += r.GetPointsPurchaseDayIsOdd()
-	points += r.GetPointsItemsDescription()
-	points += r.GetPointsTimeOfPurchase()
+func (r Receipt) categoryCount(count ItemCount, category Category) int {
+	n := 0
 
-	return points
+	for _, item := range r.Items {
+		if item.Category != category {
+			continue
+		}
+		if count == CountUnits {
+			n += item.units()
+		} else {
+			n++
+		}
+	}
+
+	return n
 }
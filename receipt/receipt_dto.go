@@ -2,46 +2,92 @@ package receipt
 
 import (
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/gmr458/receipt-processor/validator"
 )
 
 type ReceiptDTO struct {
-	Retailer     string    `json:"retailer"`
-	PurchaseDate string    `json:"purchaseDate"`
-	PurchaseTime string    `json:"purchaseTime"`
-	Total        float64   `json:"total"`
-	Items        []ItemDTO `json:"items"`
+	Retailer     string `json:"retailer"`
+	PurchaseDate string `json:"purchaseDate"`
+	PurchaseTime string `json:"purchaseTime"`
+	// Timezone is where the purchase happened, an IANA timezone name or a
+	// UTC offset. Empty means UTC.
+	Timezone string    `json:"timezone"`
+	Total    Money     `json:"total"`
+	Items    []ItemDTO `json:"items"`
+
+	// Adjustments are optional, the total must equal the items plus the
+	// adjustments.
+	Adjustments []AdjustmentDTO `json:"adjustments,omitempty"`
 }
 
-func (dto ReceiptDTO) IsValid() (bool, map[string]string) {
+func (dto ReceiptDTO) IsValid(policy ValidationPolicy) (bool, map[string][]string) {
 	v := validator.New()
 
-	dto.ValidateRetailer(v)
-	dto.ValidatePurchaseDate(v)
+	dto.ValidateRetailer(v, policy)
+	dto.ValidatePurchaseDate(v, policy)
 	dto.ValidatePurchaseTime(v)
+	dto.ValidateTimezone(v)
 	dto.ValidateTotal(v)
-	dto.ValidateItems(v)
+	dto.ValidateItems(v, policy)
+	dto.ValidateAdjustments(v, policy)
 	dto.ValidateTotalEqualItemsTotal(v)
 
 	return v.Ok(), v.Errors
 }
 
-func (dto ReceiptDTO) ValidateRetailer(v *validator.Validator) {
+func (dto ReceiptDTO) ValidateRetailer(v *validator.Validator, policy ValidationPolicy) {
 	const key = "retailer"
-	const maxLen = 50
 	retailerLen := len(dto.Retailer)
 
 	v.Check(dto.Retailer != "", key, "retailer cannot be empty")
-	v.Check(retailerLen <= maxLen, key, fmt.Sprintf("retailer max length is %d characters", maxLen))
+	v.Check(
+		retailerLen <= policy.RetailerMaxLength,
+		key,
+		fmt.Sprintf("retailer max length is %d characters", policy.RetailerMaxLength),
+	)
+
+	for _, r := range dto.Retailer {
+		if !policy.allowsRetailerRune(r) {
+			v.AddError(key, fmt.Sprintf(
+				"retailer contains %q, it may only contain %s",
+				r,
+				characterClassNames(policy.RetailerCharacters),
+			))
+			break
+		}
+	}
 }
 
-func (dto ReceiptDTO) ValidatePurchaseDate(v *validator.Validator) {
+// ValidatePurchaseDate checks the format of the purchase date and, when the
+// purchase time and timezone are valid too, that the purchase happened in
+// the window the policy allows.
+func (dto ReceiptDTO) ValidatePurchaseDate(v *validator.Validator, policy ValidationPolicy) {
 	const key = "purchaseDate"
 	_, err := time.Parse("2006-01-02", dto.PurchaseDate)
 	v.Check(err == nil, key, "invalid format, it should be YYYY-MM-DD")
+
+	loc, err := LoadTimezone(dto.Timezone)
+	if err != nil {
+		return
+	}
+	purchasedAt, err := time.ParseInLocation("2006-01-02 15:04", dto.PurchaseDate+" "+dto.PurchaseTime, loc)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	v.Check(
+		policy.AllowFuturePurchases || !purchasedAt.After(now),
+		key,
+		"the purchase date cannot be in the future",
+	)
+	v.Check(
+		policy.MaxPurchaseAgeDays == 0 || !purchasedAt.Before(now.AddDate(0, 0, -policy.MaxPurchaseAgeDays)),
+		key,
+		fmt.Sprintf("the purchase date cannot be more than %d days ago", policy.MaxPurchaseAgeDays),
+	)
 }
 
 func (dto ReceiptDTO) ValidatePurchaseTime(v *validator.Validator) {
@@ -50,56 +96,79 @@ func (dto ReceiptDTO) ValidatePurchaseTime(v *validator.Validator) {
 	v.Check(err == nil, key, "invalid format, it should be hh:mm")
 }
 
+func (dto ReceiptDTO) ValidateTimezone(v *validator.Validator) {
+	const key = "timezone"
+	_, err := LoadTimezone(dto.Timezone)
+	v.Check(err == nil, key, "invalid timezone, it should be an IANA name or a UTC offset like -05:00")
+}
+
 func (dto ReceiptDTO) ValidateTotal(v *validator.Validator) {
 	const key = "total"
 
-	v.Check(dto.Total > 0.0, key, "the total must be greater than 0.0")
+	v.Check(dto.Total > 0, key, "the total must be greater than 0.00")
 }
 
-func (dto ReceiptDTO) ValidateItems(v *validator.Validator) {
+// ValidateItems reports the errors of each item under its path, like
+// items[2].price.
+func (dto ReceiptDTO) ValidateItems(v *validator.Validator, policy ValidationPolicy) {
 	const key = "items"
-	const maxLenShortDesc = 100
 
-	v.Check(dto.Items != nil, key, "items cannot be null")
+	if dto.Items == nil {
+		v.AddError(key, "items cannot be null")
+		return
+	}
 	v.Check(len(dto.Items) != 0, key, "items cannot be empty")
+	v.Check(
+		len(dto.Items) <= policy.MaxItems,
+		key,
+		fmt.Sprintf("a receipt can have at most %d items", policy.MaxItems),
+	)
 
-	for _, item := range dto.Items {
-		lenShortDesc := len(item.ShortDescription)
-		v.Check(item.ShortDescription != "",
-			key,
-			"there are one or more items that have an empty shortDescription",
-		)
-		v.Check(
-			lenShortDesc <= maxLenShortDesc,
-			key,
-			fmt.Sprintf(
-				"the length of shortDescription must be a maximum of %d characters",
-				maxLenShortDesc,
-			),
-		)
-		v.Check(
-			item.Price > 0.0,
-			key,
-			"there is one or more items that have a price of zero or less",
-		)
+	for i, item := range dto.Items {
+		item.validate(v, validator.Index(key, i), policy)
+	}
+}
+
+// ValidateAdjustments reports the errors of each adjustment under its path,
+// like adjustments[0].kind.
+func (dto ReceiptDTO) ValidateAdjustments(v *validator.Validator, policy ValidationPolicy) {
+	const key = "adjustments"
+
+	for i, adjustment := range dto.Adjustments {
+		adjustment.validate(v, validator.Index(key, i), policy)
 	}
 }
 
+// ValidateTotalEqualItemsTotal checks the total against the items and, if
+// any, the adjustments.
 func (dto ReceiptDTO) ValidateTotalEqualItemsTotal(v *validator.Validator) {
 	const key = "total"
 
-	itemsTotal := 0.0
+	var itemsTotal Money
 	for _, item := range dto.Items {
 		itemsTotal += item.Price
 	}
 
-	totalFormatted := strconv.FormatFloat(dto.Total, 'f', 2, 64)
-	itemsTotalFormatted := strconv.FormatFloat(itemsTotal, 'f', 2, 64)
+	if len(dto.Adjustments) == 0 {
+		message := fmt.Sprintf(
+			"total field should be equal to the sum of all items price, total=%s != itemsTotal=%s",
+			dto.Total,
+			itemsTotal,
+		)
+		v.Check(dto.Total == itemsTotal, key, message)
+		return
+	}
+
+	var adjustmentsTotal Money
+	for _, adjustment := range dto.Adjustments {
+		adjustmentsTotal += Adjustment(adjustment).Signed()
+	}
 
 	message := fmt.Sprintf(
-		"total field should be equal to the sum of all items price, total=%.2f != itemsTotal=%.2f",
+		"total field should be equal to the sum of all items price plus adjustments, total=%s != itemsTotal=%s + adjustments=%s",
 		dto.Total,
 		itemsTotal,
+		adjustmentsTotal,
 	)
-	v.Check(totalFormatted == itemsTotalFormatted, key, message)
+	v.Check(dto.Total == itemsTotal+adjustmentsTotal, key, message)
 }
@@ -19,32 +19,31 @@ func TestCalculatePoints(t *testing.T) {
 	}{
 		{
 			receipt: Receipt{
-				Retailer:     "Target",
-				PurchaseDate: time.Date(2022, time.January, 1, 13, 1, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, 1, 1, 13, 1, 0, 0, time.Local),
+				Retailer:    "Target",
+				PurchasedAt: time.Date(2022, time.January, 1, 13, 1, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Mountain Dew 12PK",
-						Price:            6.49,
+						Price:            money("6.49"),
 					},
 					{
 						ShortDescription: "Emils Cheese Pizza",
-						Price:            12.25,
+						Price:            money("12.25"),
 					},
 					{
 						ShortDescription: "Knorr Creamy Chicken",
-						Price:            1.26,
+						Price:            money("1.26"),
 					},
 					{
 						ShortDescription: "Doritos Nacho Cheese",
-						Price:            3.35,
+						Price:            money("3.35"),
 					},
 					{
 						ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ",
-						Price:            12.00,
+						Price:            money("12.00"),
 					},
 				},
-				Total: 35.35,
+				Total: money("35.35"),
 			},
 			expectedPointsTotal:             28,
 			expectedPointsRetailerName:      6,
@@ -57,28 +56,27 @@ func TestCalculatePoints(t *testing.T) {
 		},
 		{
 			receipt: Receipt{
-				Retailer:     "M&M Corner Market",
-				PurchaseDate: time.Date(2022, time.March, 20, 14, 33, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, time.March, 20, 14, 33, 0, 0, time.Local),
+				Retailer:    "M&M Corner Market",
+				PurchasedAt: time.Date(2022, time.March, 20, 14, 33, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Gatorade",
-						Price:            2.25,
+						Price:            money("2.25"),
 					},
 					{
 						ShortDescription: "Gatorade",
-						Price:            2.25,
+						Price:            money("2.25"),
 					},
 					{
 						ShortDescription: "Gatorade",
-						Price:            2.25,
+						Price:            money("2.25"),
 					},
 					{
 						ShortDescription: "Gatorade",
-						Price:            2.25,
+						Price:            money("2.25"),
 					},
 				},
-				Total: 9.00,
+				Total: money("9.00"),
 			},
 			expectedPointsTotal:             109,
 			expectedPointsRetailerName:      14,
@@ -91,16 +89,15 @@ func TestCalculatePoints(t *testing.T) {
 		},
 		{
 			receipt: Receipt{
-				Retailer:     "Test Store",
-				PurchaseDate: time.Date(2022, time.January, 1, 15, 0, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, 1, 1, 15, 0, 0, 0, time.Local),
+				Retailer:    "Test Store",
+				PurchasedAt: time.Date(2022, time.January, 1, 15, 0, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Item",
-						Price:            1.00,
+						Price:            money("1.00"),
 					},
 				},
-				Total: 1.00,
+				Total: money("1.00"),
 			},
 			expectedPointsTotal:             100,
 			expectedPointsRetailerName:      9,
@@ -113,16 +110,15 @@ func TestCalculatePoints(t *testing.T) {
 		},
 		{
 			receipt: Receipt{
-				Retailer:     "Test Store",
-				PurchaseDate: time.Date(2022, time.January, 1, 14, 0, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, 1, 1, 14, 0, 0, 0, time.Local),
+				Retailer:    "Test Store",
+				PurchasedAt: time.Date(2022, time.January, 1, 14, 0, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Item",
-						Price:            1.00,
+						Price:            money("1.00"),
 					},
 				},
-				Total: 1.00,
+				Total: money("1.00"),
 			},
 			expectedPointsTotal:             90,
 			expectedPointsRetailerName:      9,
@@ -135,16 +131,15 @@ func TestCalculatePoints(t *testing.T) {
 		},
 		{
 			receipt: Receipt{
-				Retailer:     "Test Store",
-				PurchaseDate: time.Date(2022, time.January, 1, 15, 59, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, 1, 1, 15, 59, 0, 0, time.Local),
+				Retailer:    "Test Store",
+				PurchasedAt: time.Date(2022, time.January, 1, 15, 59, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Item",
-						Price:            1.00,
+						Price:            money("1.00"),
 					},
 				},
-				Total: 1.00,
+				Total: money("1.00"),
 			},
 			expectedPointsTotal:             100,
 			expectedPointsRetailerName:      9,
@@ -157,16 +152,15 @@ func TestCalculatePoints(t *testing.T) {
 		},
 		{
 			receipt: Receipt{
-				Retailer:     "Test Store",
-				PurchaseDate: time.Date(2022, time.January, 1, 16, 0, 0, 0, time.Local),
-				PurchaseTime: time.Date(2022, 1, 1, 16, 0, 0, 0, time.Local),
+				Retailer:    "Test Store",
+				PurchasedAt: time.Date(2022, time.January, 1, 16, 0, 0, 0, time.UTC),
 				Items: []Item{
 					{
 						ShortDescription: "Item",
-						Price:            1.00,
+						Price:            money("1.00"),
 					},
 				},
-				Total: 1.00,
+				Total: money("1.00"),
 			},
 			expectedPointsTotal:             90,
 			expectedPointsRetailerName:      9,
@@ -204,7 +198,7 @@ func TestCalculatePoints(t *testing.T) {
 			)
 		}
 
-		totalIsMultipleOfPoints := tt.receipt.GetPointsTotalIsMultipleOf(0.25)
+		totalIsMultipleOfPoints := tt.receipt.GetPointsTotalIsMultipleOf(money("0.25"))
 		if totalIsMultipleOfPoints != tt.expectedPointsTotalIsMultipleOf {
 			t.Errorf(
 				"expected total is multiple of 0.25 points to be %d. got %d",
@@ -245,3 +239,11 @@ func TestCalculatePoints(t *testing.T) {
 		}
 	}
 }
+
+func money(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
@@ -0,0 +1,300 @@
+package receipt
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+type RuleKind string
+
+const (
+	RuleRetailerName    RuleKind = "retailer_name"
+	RuleRoundDollar     RuleKind = "round_dollar"
+	RuleTotalMultipleOf RuleKind = "total_multiple_of"
+	RuleEveryNItems     RuleKind = "every_n_items"
+	RuleOddPurchaseDay  RuleKind = "odd_purchase_day"
+	RuleItemDescription RuleKind = "item_description"
+	RuleTimeOfPurchase  RuleKind = "time_of_purchase"
+	RuleItemCategory    RuleKind = "item_category"
+)
+
+// Basis is the amount round_dollar and total_multiple_of rules score on, the
+// total when empty.
+type Basis string
+
+const (
+	BasisTotal    Basis = "total"
+	BasisSubtotal Basis = "subtotal"
+)
+
+// label names the amount in explanations.
+func (b Basis) label() string {
+	if b == "" {
+		return string(BasisTotal)
+	}
+
+	return string(b)
+}
+
+// ItemCount is how every_n_items and item_category rules count items, by
+// line when empty.
+type ItemCount string
+
+const (
+	CountLines ItemCount = "lines"
+	CountUnits ItemCount = "units"
+)
+
+// label names what is counted in explanations.
+func (c ItemCount) label() string {
+	if c == CountUnits {
+		return "units"
+	}
+
+	return "items"
+}
+
+// Rule is a single scoring rule. Points is the value the rule awards when it
+// matches; for retailer_name it is awarded per alphanumeric character, for
+// every_n_items per group of items and for item_category per item, or unit,
+// in the category. item_description derives its points from the item prices and
+// ignores Points.
+type Rule struct {
+	Name   string     `json:"name" yaml:"name"`
+	Kind   RuleKind   `json:"kind" yaml:"kind"`
+	Points int        `json:"points,omitempty" yaml:"points,omitempty"`
+	Params RuleParams `json:"params,omitzero" yaml:"params,omitempty"`
+}
+
+// RuleParams holds the parameters of every built-in rule kind, only the ones
+// relevant to a rule's kind are read.
+type RuleParams struct {
+	// round_dollar, total_multiple_of: whether the rule scores on the
+	// subtotal of the items or the total including adjustments.
+	Basis Basis `json:"basis,omitempty" yaml:"basis,omitempty"`
+
+	// total_multiple_of: the amount must be a multiple of this one.
+	Multiple Money `json:"multiple,omitempty" yaml:"multiple,omitempty"`
+
+	// every_n_items, item_category: whether an item of several units counts
+	// once or once per unit.
+	Count ItemCount `json:"count,omitempty" yaml:"count,omitempty"`
+
+	// every_n_items: size of each group of items.
+	Every int `json:"every,omitempty" yaml:"every,omitempty"`
+
+	// item_description: the trimmed description length must be a multiple of
+	// LengthMultiple, the item then earns ceil(price * PriceMultiplier).
+	LengthMultiple  int     `json:"lengthMultiple,omitempty" yaml:"lengthMultiple,omitempty"`
+	PriceMultiplier float64 `json:"priceMultiplier,omitempty" yaml:"priceMultiplier,omitempty"`
+
+	// time_of_purchase: exclusive hh:mm window.
+	Start string `json:"start,omitempty" yaml:"start,omitempty"`
+	End   string `json:"end,omitempty" yaml:"end,omitempty"`
+
+	// item_category: the category whose items earn points.
+	Category Category `json:"category,omitempty" yaml:"category,omitempty"`
+}
+
+// Ruleset is a versioned set of rules. A version must never be reused for a
+// different set of rules, receipts record the version they were scored with.
+type Ruleset struct {
+	Version string `json:"version" yaml:"version"`
+	Rules   []Rule `json:"rules" yaml:"rules"`
+}
+
+//go:embed rulesets/default.json
+var defaultRulesetJSON []byte
+
+var defaultRuleset = sync.OnceValue(func() Ruleset {
+	ruleset, err := ParseRuleset(defaultRulesetJSON)
+	if err != nil {
+		panic("invalid default ruleset: " + err.Error())
+	}
+	return ruleset
+})
+
+// DefaultRuleset returns the built-in ruleset, it reproduces the scoring the
+// service has always used.
+func DefaultRuleset() Ruleset {
+	return defaultRuleset()
+}
+
+// LoadRuleset reads and validates a ruleset file, YAML when its extension is
+// .yaml or .yml and JSON otherwise.
+func LoadRuleset(path string) (Ruleset, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("failed to read ruleset file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseRulesetYAML(b)
+	default:
+		return ParseRuleset(b)
+	}
+}
+
+// ParseRuleset decodes and validates a JSON ruleset.
+func ParseRuleset(b []byte) (Ruleset, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	var ruleset Ruleset
+	if err := decoder.Decode(&ruleset); err != nil {
+		return Ruleset{}, fmt.Errorf("failed to decode ruleset: %w", err)
+	}
+
+	return validateRuleset(ruleset)
+}
+
+// ParseRulesetYAML decodes and validates a YAML ruleset, its fields are the
+// ones of a JSON ruleset.
+func ParseRulesetYAML(b []byte) (Ruleset, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+
+	var ruleset Ruleset
+	if err := decoder.Decode(&ruleset); err != nil {
+		return Ruleset{}, fmt.Errorf("failed to decode ruleset: %w", err)
+	}
+
+	return validateRuleset(ruleset)
+}
+
+func validateRuleset(ruleset Ruleset) (Ruleset, error) {
+	isValid, errors := ruleset.IsValid()
+	if !isValid {
+		return Ruleset{}, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid ruleset",
+			Details: errors,
+		}
+	}
+
+	return ruleset, nil
+}
+
+func (rs Ruleset) IsValid() (bool, map[string][]string) {
+	v := validator.New()
+
+	v.Check(rs.Version != "", "version", "version cannot be empty")
+	v.Check(len(rs.Rules) != 0, "rules", "rules cannot be empty")
+
+	for i, rule := range rs.Rules {
+		rule.validate(v, validator.Index("rules", i))
+	}
+
+	return v.Ok(), v.Errors
+}
+
+func (rule Rule) validate(v *validator.Validator, key string) {
+	v.Check(rule.Name != "", validator.Field(key, "name"), "name cannot be empty")
+	v.Check(rule.Points >= 0, validator.Field(key, "points"), "points cannot be negative")
+
+	params := validator.Field(key, "params")
+
+	switch rule.Kind {
+	case RuleRoundDollar, RuleTotalMultipleOf:
+		v.Check(
+			rule.Params.Basis == "" || rule.Params.Basis == BasisTotal || rule.Params.Basis == BasisSubtotal,
+			validator.Field(params, "basis"),
+			fmt.Sprintf("basis must be %s or %s", BasisTotal, BasisSubtotal),
+		)
+	default:
+		v.Check(rule.Params.Basis == "", validator.Field(params, "basis"), "basis only applies to round_dollar and total_multiple_of rules")
+	}
+
+	switch rule.Kind {
+	case RuleEveryNItems, RuleItemCategory:
+		v.Check(
+			rule.Params.Count == "" || rule.Params.Count == CountLines || rule.Params.Count == CountUnits,
+			validator.Field(params, "count"),
+			fmt.Sprintf("count must be %s or %s", CountLines, CountUnits),
+		)
+	default:
+		v.Check(rule.Params.Count == "", validator.Field(params, "count"), "count only applies to every_n_items and item_category rules")
+	}
+
+	switch rule.Kind {
+	case RuleRetailerName, RuleRoundDollar, RuleOddPurchaseDay:
+	case RuleTotalMultipleOf:
+		v.Check(rule.Params.Multiple > 0, validator.Field(params, "multiple"), "multiple must be greater than zero")
+	case RuleEveryNItems:
+		v.Check(rule.Params.Every > 0, validator.Field(params, "every"), "every must be greater than zero")
+	case RuleItemDescription:
+		v.Check(rule.Params.LengthMultiple > 0, validator.Field(params, "lengthMultiple"), "lengthMultiple must be greater than zero")
+		v.Check(rule.Params.PriceMultiplier > 0, validator.Field(params, "priceMultiplier"), "priceMultiplier must be greater than zero")
+	case RuleTimeOfPurchase:
+		start, errStart := time.Parse("15:04", rule.Params.Start)
+		end, errEnd := time.Parse("15:04", rule.Params.End)
+		v.Check(errStart == nil, validator.Field(params, "start"), "invalid format, it should be hh:mm")
+		v.Check(errEnd == nil, validator.Field(params, "end"), "invalid format, it should be hh:mm")
+		v.Check(errStart != nil || errEnd != nil || start.Before(end), validator.Field(params, "end"), "end must be after start")
+	case RuleItemCategory:
+		v.Check(rule.Params.Category.IsValid(), validator.Field(params, "category"), fmt.Sprintf("unknown category %q", rule.Params.Category))
+	default:
+		v.AddError(validator.Field(key, "kind"), fmt.Sprintf("unknown rule kind %q", rule.Kind))
+	}
+}
+
+// Apply returns the points the rule awards to the receipt.
+func (rule Rule) Apply(r Receipt) int {
+	switch rule.Kind {
+	case RuleRetailerName:
+		return r.pointsRetailerName(rule.Points)
+	case RuleRoundDollar:
+		return r.pointsRoundDollar(rule.Params.Basis, rule.Points)
+	case RuleTotalMultipleOf:
+		return r.pointsTotalIsMultipleOf(rule.Params.Basis, rule.Params.Multiple, rule.Points)
+	case RuleEveryNItems:
+		return r.pointsForEveryNItems(rule.Params.Count, rule.Params.Every, rule.Points)
+	case RuleOddPurchaseDay:
+		return r.pointsPurchaseDayIsOdd(rule.Points)
+	case RuleItemDescription:
+		return r.pointsItemsDescription(rule.Params.LengthMultiple, rule.Params.PriceMultiplier)
+	case RuleTimeOfPurchase:
+		return r.pointsTimeOfPurchase(
+			minuteOfDay(rule.Params.Start),
+			minuteOfDay(rule.Params.End),
+			rule.Points,
+		)
+	case RuleItemCategory:
+		return r.pointsItemCategory(rule.Params.Count, rule.Params.Category, rule.Points)
+	}
+
+	panic("unknown rule kind: " + string(rule.Kind))
+}
+
+// Score returns the sum of the points awarded by every rule.
+func (rs Ruleset) Score(r Receipt) int {
+	points := 0
+
+	for _, rule := range rs.Rules {
+		points += rule.Apply(r)
+	}
+
+	return points
+}
+
+func minuteOfDay(hhmm string) int {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		panic("invalid time of day: " + hhmm)
+	}
+
+	return t.Hour()*60 + t.Minute()
+}
@@ -0,0 +1,205 @@
+package receipt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestParseRulesetInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantKey string
+	}{
+		{
+			name:    "empty rules",
+			input:   `{"rules": []}`,
+			wantKey: "rules",
+		},
+		{
+			name:    "unknown kind",
+			input:   `{"rules": [{"name": "x", "kind": "moon_phase", "points": 1}]}`,
+			wantKey: "rules[0].kind",
+		},
+		{
+			name:    "missing every",
+			input:   `{"rules": [{"name": "x", "kind": "every_n_items", "points": 5}]}`,
+			wantKey: "rules[0].params.every",
+		},
+		{
+			name: "inverted window",
+			input: `{"rules": [{"name": "x", "kind": "time_of_purchase", "points": 5,
+				"params": {"start": "16:00", "end": "14:00"}}]}`,
+			wantKey: "rules[0].params.end",
+		},
+		{
+			name: "unknown basis",
+			input: `{"rules": [{"name": "x", "kind": "round_dollar", "points": 50,
+				"params": {"basis": "net"}}]}`,
+			wantKey: "rules[0].params.basis",
+		},
+		{
+			name: "unknown count",
+			input: `{"rules": [{"name": "x", "kind": "every_n_items", "points": 5,
+				"params": {"every": 2, "count": "boxes"}}]}`,
+			wantKey: "rules[0].params.count",
+		},
+		{
+			name: "basis on an item rule",
+			input: `{"rules": [{"name": "x", "kind": "every_n_items", "points": 5,
+				"params": {"every": 2, "basis": "subtotal"}}]}`,
+			wantKey: "rules[0].params.basis",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseRuleset([]byte(tt.input))
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+
+		details := errs.ErrorDetails(err)
+		if _, ok := details[tt.wantKey]; !ok {
+			t.Errorf("%s: expected error for key %q. got %v", tt.name, tt.wantKey, details)
+		}
+	}
+}
+
+func TestParseRulesetUnknownField(t *testing.T) {
+	_, err := ParseRuleset([]byte(`{"rules": [{"name": "x", "kind": "odd_purchase_day", "pts": 1}]}`))
+	if err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestRulesetCustomParams(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`{
+		"version": "test",
+		"rules": [
+			{"name": "every 3 items", "kind": "every_n_items", "points": 7, "params": {"every": 3}},
+			{"name": "morning", "kind": "time_of_purchase", "points": 4, "params": {"start": "08:00", "end": "10:00"}},
+			{"name": "multiple of 0.10", "kind": "total_multiple_of", "points": 3, "params": {"multiple": 0.10}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := Receipt{
+		Retailer:    "Target",
+		PurchasedAt: time.Date(2022, time.January, 2, 9, 15, 0, 0, time.UTC),
+		Items: []Item{
+			{ShortDescription: "A", Price: money("1.00")},
+			{ShortDescription: "B", Price: money("1.00")},
+			{ShortDescription: "C", Price: money("1.00")},
+		},
+		Total: money("3.00"),
+	}
+
+	got := ruleset.Score(rec)
+	if want := 7 + 4 + 3; got != want {
+		t.Errorf("expected score to be %d. got %d", want, got)
+	}
+}
+
+func TestLoadRulesetYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ruleset.yaml")
+	err := os.WriteFile(path, []byte(`
+version: test
+rules:
+  - name: every 3 items
+    kind: every_n_items
+    points: 7
+    params: {every: 3}
+  - name: morning
+    kind: time_of_purchase
+    points: 4
+    params: {start: "08:00", end: "10:00"}
+  - name: multiple of 0.10
+    kind: total_multiple_of
+    points: 3
+    params: {multiple: 0.10}
+`), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ruleset, err := LoadRuleset(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := Receipt{
+		Retailer:    "Target",
+		PurchasedAt: time.Date(2022, time.January, 2, 9, 15, 0, 0, time.UTC),
+		Items: []Item{
+			{ShortDescription: "A", Price: money("1.00")},
+			{ShortDescription: "B", Price: money("1.00")},
+			{ShortDescription: "C", Price: money("1.00")},
+		},
+		Total: money("3.00"),
+	}
+
+	if ruleset.Version != "test" {
+		t.Errorf("expected version test. got %q", ruleset.Version)
+	}
+	if got, want := ruleset.Score(rec), 7+4+3; got != want {
+		t.Errorf("expected score to be %d. got %d", want, got)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown field", "rules:\n  - {name: x, kind: odd_purchase_day, pts: 1}\n"},
+		{"unknown kind", "version: x\nrules:\n  - {name: x, kind: nope}\n"},
+		{"invalid multiple", "version: x\nrules:\n  - {name: x, kind: total_multiple_of, params: {multiple: [1]}}\n"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRulesetYAML([]byte(tt.input))
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestBreakdownMatchesScore(t *testing.T) {
+	rec := Receipt{
+		Retailer:    "M&M Corner Market",
+		PurchasedAt: time.Date(2022, time.March, 20, 14, 33, 0, 0, time.UTC),
+		Items: []Item{
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+			{ShortDescription: "Gatorade", Price: money("2.25")},
+		},
+		Total: money("9.00"),
+	}
+
+	ruleset := DefaultRuleset()
+	breakdown := ruleset.Breakdown(rec)
+
+	if breakdown.Points != ruleset.Score(rec) {
+		t.Errorf("expected breakdown points to be %d. got %d", ruleset.Score(rec), breakdown.Points)
+	}
+	if len(breakdown.Rules) != len(ruleset.Rules) {
+		t.Fatalf("expected %d rule results. got %d", len(ruleset.Rules), len(breakdown.Rules))
+	}
+
+	sum := 0
+	for _, result := range breakdown.Rules {
+		if result.Reason == "" {
+			t.Errorf("expected a reason for rule %q", result.Rule)
+		}
+		sum += result.Points
+	}
+	if sum != 109 {
+		t.Errorf("expected rule results to add up to 109. got %d", sum)
+	}
+}
@@ -0,0 +1,82 @@
+package receipt
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// Rulesets holds the current ruleset together with every historical version
+// that receipts may have been scored with.
+type Rulesets struct {
+	current  string
+	versions map[string]Ruleset
+}
+
+// NewRulesets registers current and the historical rulesets. Registering the
+// same version twice is only allowed when both definitions are identical.
+func NewRulesets(current Ruleset, history ...Ruleset) (Rulesets, error) {
+	rs := Rulesets{
+		current:  current.Version,
+		versions: make(map[string]Ruleset, len(history)+1),
+	}
+
+	for _, ruleset := range append([]Ruleset{current}, history...) {
+		registered, exists := rs.versions[ruleset.Version]
+		if exists && !reflect.DeepEqual(registered, ruleset) {
+			return Rulesets{}, fmt.Errorf("conflicting definitions for ruleset version %q", ruleset.Version)
+		}
+		rs.versions[ruleset.Version] = ruleset
+	}
+
+	return rs, nil
+}
+
+// LoadRulesetDir loads every *.json, *.yaml and *.yml ruleset in dir.
+func LoadRulesetDir(dir string) ([]Ruleset, error) {
+	paths := []string{}
+	for _, pattern := range []string{"*.json", "*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob ruleset files: %w", err)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	rulesets := make([]Ruleset, 0, len(paths))
+	for _, path := range paths {
+		ruleset, err := LoadRuleset(path)
+		if err != nil {
+			return nil, fmt.Errorf("ruleset %q: %w", path, err)
+		}
+		rulesets = append(rulesets, ruleset)
+	}
+
+	return rulesets, nil
+}
+
+func (rs Rulesets) Current() Ruleset {
+	return rs.versions[rs.current]
+}
+
+// Get returns the ruleset registered under version, the current one if
+// version is empty.
+func (rs Rulesets) Get(version string) (Ruleset, error) {
+	if version == "" {
+		return rs.Current(), nil
+	}
+
+	ruleset, ok := rs.versions[version]
+	if !ok {
+		return Ruleset{}, &errs.Error{
+			Code:    errs.ENOTFOUND,
+			Message: fmt.Sprintf("Ruleset version %q not found", version),
+		}
+	}
+
+	return ruleset, nil
+}
@@ -0,0 +1,55 @@
+package receipt
+
+import (
+	"testing"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestRulesets(t *testing.T) {
+	v1 := DefaultRuleset()
+	v2 := Ruleset{
+		Version: "v2",
+		Rules: []Rule{
+			{Name: "odd day", Kind: RuleOddPurchaseDay, Points: 12},
+		},
+	}
+
+	rulesets, err := NewRulesets(v2, v1, DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rulesets.Current().Version; got != "v2" {
+		t.Errorf("expected current version to be v2. got %s", got)
+	}
+
+	ruleset, err := rulesets.Get("v1")
+	if err != nil || ruleset.Version != "v1" {
+		t.Errorf("expected ruleset v1. got %q, %v", ruleset.Version, err)
+	}
+
+	ruleset, err = rulesets.Get("")
+	if err != nil || ruleset.Version != "v2" {
+		t.Errorf("expected the current ruleset for an empty version. got %q, %v", ruleset.Version, err)
+	}
+
+	_, err = rulesets.Get("v3")
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected not found for an unknown version. got %v", err)
+	}
+}
+
+func TestRulesetsConflictingVersion(t *testing.T) {
+	conflicting := Ruleset{
+		Version: "v1",
+		Rules: []Rule{
+			{Name: "odd day", Kind: RuleOddPurchaseDay, Points: 12},
+		},
+	}
+
+	_, err := NewRulesets(DefaultRuleset(), conflicting)
+	if err == nil {
+		t.Error("expected an error for two different rulesets sharing a version")
+	}
+}
@@ -2,7 +2,9 @@ package receipt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,117 +14,451 @@ import (
 
 type Service struct {
 	repository ReceiptRepository
+	campaigns  CampaignRepository
 	cache      ReceiptCache
+	rulesets   Rulesets
+	classifier Classifier
+	duplicates DuplicatePolicy
+	policy     ValidationPolicy
 }
 
-func NewService(repository ReceiptRepository, cache ReceiptCache) Service {
+func NewService(
+	repository ReceiptRepository,
+	campaigns CampaignRepository,
+	cache ReceiptCache,
+	rulesets Rulesets,
+	classifier Classifier,
+	duplicates DuplicatePolicy,
+	policy ValidationPolicy,
+) Service {
 	return Service{
 		repository,
+		campaigns,
 		cache,
+		rulesets,
+		classifier,
+		duplicates,
+		policy,
 	}
 }
 
 func (s *Service) Process(ctx context.Context, dto ReceiptDTO) (*Receipt, error) {
-	isValid, errors := dto.IsValid()
-	if !isValid {
-		return nil, &errs.Error{
-			Code:    errs.EINVALID,
-			Message: "Invalid field/s",
-			Details: errors,
-		}
+	return s.processAs(ctx, uuid.New().String(), dto)
+}
+
+// processAs processes dto into a receipt with the given ID.
+func (s *Service) processAs(ctx context.Context, id string, dto ReceiptDTO) (*Receipt, error) {
+	rec, err := newReceipt(dto, s.policy, s.classifier)
+	if err != nil {
+		return nil, err
 	}
+	rec.ID = id
 
-	rec := &Receipt{
-		ID:       uuid.New().String(),
-		Retailer: dto.Retailer,
-		Total:    dto.Total,
-		Items:    make([]Item, 0, len(dto.Items)),
+	originalID, err := s.findOriginal(ctx, rec.Fingerprint)
+	if err != nil {
+		return nil, err
 	}
-	parsedDate, err := time.Parse("2006-01-02", dto.PurchaseDate)
+
+	breakdown, err := s.score(ctx, rec, s.rulesets.Current())
 	if err != nil {
-		return nil, &errs.Error{
-			Code:    errs.EINVALID,
-			Message: "Invalid field/s",
-			Details: map[string]string{
-				"purchaseDate": "invalid format, it should be YYYY-MM-DD",
-			},
-		}
+		return nil, err
+	}
+
+	err = s.markDuplicate(rec, originalID, &breakdown)
+	if err != nil {
+		return nil, err
 	}
-	rec.PurchaseDate = parsedDate
+	rec.Points = &breakdown.Points
+	rec.RulesetVersion = breakdown.RulesetVersion
+	rec.Campaigns = breakdown.Campaigns
 
-	parsedTime, err := time.Parse("15:04", dto.PurchaseTime)
+	err = s.repository.Create(ctx, rec)
+	if errors.Is(err, ErrOriginalExists) {
+		err = s.refindOriginal(ctx, rec, &breakdown)
+		if err != nil {
+			return nil, err
+		}
+		err = s.repository.Create(ctx, rec)
+	}
 	if err != nil {
+		return nil, err
+	}
+
+	_ = s.cache.SetScoreById(
+		ctx,
+		rec.ID,
+		Score{Points: breakdown.Points, RulesetVersion: breakdown.RulesetVersion},
+		5*time.Minute,
+	)
+
+	return rec, nil
+}
+
+// ProcessBatch validates every receipt on its own, then scores and stores the
+// valid ones together. The results follow the order of dtos. Invalid receipts
+// don't prevent the others from being stored.
+func (s *Service) ProcessBatch(ctx context.Context, dtos []ReceiptDTO) ([]BatchResult, error) {
+	results, err := s.processBatch(ctx, dtos)
+	if errors.Is(err, ErrOriginalExists) {
+		// Concurrent requests stored originals of some of the receipts after
+		// they were looked up, processing the batch again finds them.
+		results, err = s.processBatch(ctx, dtos)
+	}
+
+	return results, err
+}
+
+func (s *Service) processBatch(ctx context.Context, dtos []ReceiptDTO) ([]BatchResult, error) {
+	if len(dtos) == 0 {
 		return nil, &errs.Error{
 			Code:    errs.EINVALID,
 			Message: "Invalid field/s",
-			Details: map[string]string{
-				"purchaseTime": "invalid format, it should be hh:mm",
+			Details: map[string][]string{
+				"receipts": {"receipts cannot be empty"},
 			},
 		}
 	}
-	rec.PurchaseTime = parsedTime
 
-	for _, itemDto := range dto.Items {
-		item := Item{
-			ID:               uuid.New().String(),
-			ShortDescription: itemDto.ShortDescription,
-			Price:            itemDto.Price,
+	// Campaigns are loaded once for the whole batch, ApplyCampaigns skips the
+	// ones inactive at each receipt's purchase time.
+	campaigns, err := s.campaigns.Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleset := s.rulesets.Current()
+	results := make([]BatchResult, len(dtos))
+	receipts := make([]*Receipt, 0, len(dtos))
+
+	// originals holds the fingerprints of the receipts accepted so far, a
+	// batch may contain the same receipt twice.
+	originals := make(map[string]string, len(dtos))
+
+	for i, dto := range dtos {
+		results[i].Index = i
+
+		rec, err := newReceipt(dto, s.policy, s.classifier)
+		if err != nil {
+			if errs.ErrorCode(err) != errs.EINVALID {
+				return nil, err
+			}
+			results[i].Errors = errs.ErrorDetails(err)
+			continue
 		}
-		rec.Items = append(rec.Items, item)
+
+		originalID, ok := originals[rec.Fingerprint]
+		if !ok {
+			originalID, err = s.findOriginal(ctx, rec.Fingerprint)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		breakdown := scoreWithCampaigns(rec, ruleset, campaigns)
+		err = s.markDuplicate(rec, originalID, &breakdown)
+		if err != nil {
+			results[i].Errors = errs.ErrorDetails(err)
+			continue
+		}
+		if rec.DuplicateOf == "" {
+			originals[rec.Fingerprint] = rec.ID
+		}
+		rec.Points = &breakdown.Points
+		rec.RulesetVersion = breakdown.RulesetVersion
+		rec.Campaigns = breakdown.Campaigns
+
+		results[i].ID = rec.ID
+		results[i].Points = rec.Points
+		receipts = append(receipts, rec)
 	}
 
-	err = s.repository.Create(ctx, rec)
+	if len(receipts) == 0 {
+		return results, nil
+	}
+
+	err = s.repository.CreateBatch(ctx, receipts)
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		_ = s.cache.SetPointsById(
-			context.Background(),
+	for _, rec := range receipts {
+		_ = s.cache.SetScoreById(
+			ctx,
 			rec.ID,
-			rec.CalculateTotalPoints(),
+			Score{Points: *rec.Points, RulesetVersion: rec.RulesetVersion},
 			5*time.Minute,
 		)
-	}()
+	}
 
-	return rec, nil
+	return results, nil
+}
+
+// Score validates and scores a receipt, campaigns included, without
+// persisting or caching it. An empty version scores with the current ruleset.
+func (s *Service) Score(ctx context.Context, dto ReceiptDTO, version string) (PointsBreakdown, error) {
+	ruleset, err := s.rulesets.Get(version)
+	if err != nil {
+		return PointsBreakdown{}, err
+	}
+
+	rec, err := newReceipt(dto, s.policy, s.classifier)
+	if err != nil {
+		return PointsBreakdown{}, err
+	}
+
+	return s.score(ctx, rec, ruleset)
 }
 
-func (s *Service) GetPointsById(ctx context.Context, id string) (int, error) {
+// GetById returns the full receipt. Receipts created before points were
+// recorded get the points of the ruleset version they carry. Soft deleted
+// receipts are only found if includeDeleted is set.
+func (s *Service) GetById(ctx context.Context, id string, includeDeleted bool) (*Receipt, error) {
 	err := uuid.Validate(id)
 	if err != nil {
-		return 0, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
 	}
 
-	points, err := s.cache.GetPointsById(ctx, id)
-	if nil == err {
-		return points, nil
+	cached, err := s.cache.GetReceiptById(ctx, id)
+	if nil == err && cached.DeletedAt == nil {
+		return cached, nil
 	}
 
-	receipt, err := s.repository.FindById(ctx, id)
+	receipt, err := s.findVisible(ctx, id, includeDeleted)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	points = receipt.CalculateTotalPoints()
+	if receipt.Points == nil {
+		score, err := s.recordedScore(receipt)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Points = &score.Points
+	}
 
-	go func() {
-		_ = s.cache.SetPointsById(
-			context.Background(),
-			receipt.ID,
-			points,
-			5*time.Minute,
-		)
-	}()
+	// Cached before returning: filled in the background, the copy could land
+	// after an amendment purged it and serve the old receipt until it expires.
+	// Deleted receipts aren't cached, the cache serves every caller.
+	if receipt.DeletedAt == nil {
+		_ = s.cache.SetReceiptById(ctx, receipt, 5*time.Minute)
+	}
 
-	return points, nil
+	return receipt, nil
+}
+
+// Update replaces the receipt with the one dto describes and rescores it with
+// the current ruleset and campaigns. The version it replaces is kept as a
+// revision.
+func (s *Service) Update(ctx context.Context, id string, dto ReceiptDTO) (*Receipt, error) {
+	current, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.amend(ctx, current, dto)
+}
+
+// Patch amends the receipt with a JSON Merge Patch of its ReceiptDTO, see
+// Update.
+func (s *Service) Patch(ctx context.Context, id string, patch map[string]any) (*Receipt, error) {
+	current, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto, err := applyMergePatch(current.DTO(), patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.amend(ctx, current, dto)
+}
+
+// GetRevisions returns the previous versions of the receipt, oldest first.
+func (s *Service) GetRevisions(ctx context.Context, id string) ([]Revision, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	return s.repository.FindRevisions(ctx, id)
+}
+
+// Delete soft deletes the receipt. It is kept along with the reason but left
+// out of the receipt list.
+func (s *Service) Delete(ctx context.Context, id string, dto DeletionDTO) error {
+	err := uuid.Validate(id)
+	if err != nil {
+		return &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	isValid, errors := dto.IsValid()
+	if !isValid {
+		return &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	err = s.repository.Delete(ctx, id, strings.TrimSpace(dto.Reason), time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return err
+	}
+
+	s.purgeCache(ctx, id)
+
+	return nil
+}
+
+// Restore undoes the soft deletion of the receipt.
+func (s *Service) Restore(ctx context.Context, id string) (*Receipt, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	err = s.repository.Restore(ctx, id, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	s.purgeCache(ctx, id)
+
+	return s.repository.FindById(ctx, id)
+}
+
+// Purge removes a deleted receipt for good, revisions and attachments
+// included. It returns the removed attachments, whose content is left to
+// AttachmentService.DeleteContent.
+func (s *Service) Purge(ctx context.Context, id string) ([]Attachment, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Deleted receipt not found"}
+	}
+
+	attachments, err := s.repository.Purge(ctx, id, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	s.purgeCache(ctx, id)
+
+	return attachments, nil
+}
+
+// GetPointsById returns the points recorded for the receipt. When version is
+// set and differs from the recorded one the receipt's rules are rescored
+// under that ruleset version instead, campaigns are left out since they only
+// apply when a receipt is processed. The result is neither stored nor cached.
+// Soft deleted receipts are only found if includeDeleted is set.
+func (s *Service) GetPointsById(ctx context.Context, id, version string, includeDeleted bool) (Score, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return Score{}, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	if version == "" {
+		score, err := s.cache.GetScoreById(ctx, id)
+		if nil == err {
+			return score, nil
+		}
+	}
+
+	receipt, err := s.findVisible(ctx, id, includeDeleted)
+	if err != nil {
+		return Score{}, err
+	}
+
+	if version != "" && version != receipt.RulesetVersion {
+		ruleset, err := s.rulesets.Get(version)
+		if err != nil {
+			return Score{}, err
+		}
+		return Score{Points: ruleset.Score(*receipt), RulesetVersion: version}, nil
+	}
+
+	score, err := s.recordedScore(receipt)
+	if err != nil {
+		return Score{}, err
+	}
+
+	// Cached before returning, like GetById does with the receipt.
+	if receipt.DeletedAt == nil {
+		_ = s.cache.SetScoreById(ctx, receipt.ID, score, 5*time.Minute)
+	}
+
+	return score, nil
+}
+
+// GetPointsBreakdownById explains the receipt's recorded points, or its rules
+// rescored under version if set to a different ruleset version. Soft deleted
+// receipts are only found if includeDeleted is set.
+func (s *Service) GetPointsBreakdownById(
+	ctx context.Context,
+	id string,
+	version string,
+	includeDeleted bool,
+) (PointsBreakdown, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return PointsBreakdown{}, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	receipt, err := s.findVisible(ctx, id, includeDeleted)
+	if err != nil {
+		return PointsBreakdown{}, err
+	}
+
+	if version == "" {
+		version = receipt.RulesetVersion
+	}
+	ruleset, err := s.rulesets.Get(version)
+	if err != nil {
+		return PointsBreakdown{}, err
+	}
+
+	if version != receipt.RulesetVersion {
+		return ruleset.Breakdown(*receipt), nil
+	}
+
+	// Duplicates zeroed when they were processed score nothing under their
+	// own version.
+	if receipt.DuplicateOf != "" && receipt.Points != nil && *receipt.Points == 0 {
+		return zeroBreakdown(version, receipt.DuplicateOf), nil
+	}
+
+	breakdown := ruleset.Breakdown(*receipt)
+	breakdown.AddCampaigns(receipt.Campaigns)
+	breakdown.DuplicateOf = receipt.DuplicateOf
+
+	return breakdown, nil
+}
+
+// Simulate reports how every stored receipt's rules points would change if
+// candidate replaced the current ruleset.
+func (s *Service) Simulate(
+	ctx context.Context,
+	candidate Ruleset,
+	opts SimulationOptions,
+) (SimulationReport, error) {
+	isValid, errors := candidate.IsValid()
+	if !isValid {
+		return SimulationReport{}, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid ruleset",
+			Details: errors,
+		}
+	}
+
+	return Simulate(ctx, s.repository, s.rulesets.Current(), candidate, opts)
 }
 
 func (s *Service) GetReceipts(
 	ctx context.Context,
 	filters Filters,
 ) (PaginatedReceipts, error) {
-	isValid, errors := filters.IsValid()
+	isValid, errors := filters.IsValid(s.policy)
 	if !isValid {
 		return PaginatedReceipts{}, &errs.Error{
 			Code:    errs.EINVALID,
@@ -132,10 +468,11 @@ func (s *Service) GetReceipts(
 	}
 
 	key := fmt.Sprintf(
-		"receipts:page:%d:limit:%d:sort:%s",
+		PaginatedReceiptsKeyPrefix+"%d:limit:%d:sort:%s:deleted:%t",
 		filters.Page,
 		filters.Limit,
 		filters.Sort,
+		filters.IncludeDeleted,
 	)
 
 	paginatedReceipts, err := s.cache.GetPaginatedReceipts(ctx, key)
@@ -148,14 +485,264 @@ func (s *Service) GetReceipts(
 		return PaginatedReceipts{}, err
 	}
 
-	go func() {
-		_ = s.cache.SetPaginatedReceipts(
-			context.Background(),
-			key,
-			paginatedReceipts,
-			5*time.Minute,
-		)
-	}()
+	// Cached before returning, like GetById does with the receipt.
+	_ = s.cache.SetPaginatedReceipts(ctx, key, paginatedReceipts, 5*time.Minute)
 
 	return paginatedReceipts, nil
 }
+
+// score scores the receipt with ruleset and the campaigns active at its
+// purchase time.
+func (s *Service) score(ctx context.Context, rec *Receipt, ruleset Ruleset) (PointsBreakdown, error) {
+	campaigns, err := s.campaigns.FindActive(ctx, rec.PurchasedAt)
+	if err != nil {
+		return PointsBreakdown{}, err
+	}
+
+	return scoreWithCampaigns(rec, ruleset, campaigns), nil
+}
+
+// scoreWithCampaigns scores the receipt with ruleset and the campaigns that
+// apply to it.
+func scoreWithCampaigns(rec *Receipt, ruleset Ruleset, campaigns []Campaign) PointsBreakdown {
+	breakdown := ruleset.Breakdown(*rec)
+	breakdown.AddCampaigns(ApplyCampaigns(campaigns, *rec, breakdown.Points))
+
+	return breakdown
+}
+
+// findOriginal returns the ID of the stored receipt with the fingerprint,
+// empty if there is none.
+func (s *Service) findOriginal(ctx context.Context, fingerprint string) (string, error) {
+	id, err := s.repository.FindIdByFingerprint(ctx, fingerprint)
+	if errs.ErrorCode(err) == errs.ENOTFOUND {
+		return "", nil
+	}
+
+	return id, err
+}
+
+// markDuplicate applies the duplicate policy to rec when it duplicates the
+// receipt originalID. Rejected duplicates return a conflict error.
+func (s *Service) markDuplicate(rec *Receipt, originalID string, breakdown *PointsBreakdown) error {
+	if originalID == "" {
+		return nil
+	}
+
+	switch s.duplicates {
+	case DuplicateFlag:
+		breakdown.DuplicateOf = originalID
+	case DuplicateZero:
+		*breakdown = zeroBreakdown(breakdown.RulesetVersion, originalID)
+	default:
+		return duplicateError(originalID)
+	}
+	rec.DuplicateOf = originalID
+
+	return nil
+}
+
+// refindOriginal applies the duplicate policy to rec again after the
+// repository refused it as an original: another receipt with its fingerprint
+// was stored since findOriginal looked for one.
+func (s *Service) refindOriginal(ctx context.Context, rec *Receipt, breakdown *PointsBreakdown) error {
+	originalID, err := s.findOriginal(ctx, rec.Fingerprint)
+	if err != nil {
+		return err
+	}
+	if originalID == "" || originalID == rec.ID {
+		return ErrOriginalExists
+	}
+
+	err = s.markDuplicate(rec, originalID, breakdown)
+	if err != nil {
+		return err
+	}
+	rec.Points = &breakdown.Points
+	rec.RulesetVersion = breakdown.RulesetVersion
+	rec.Campaigns = breakdown.Campaigns
+
+	return nil
+}
+
+func (s *Service) findById(ctx context.Context, id string) (*Receipt, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	return s.repository.FindById(ctx, id)
+}
+
+// findVisible returns the receipt, a soft deleted one is only found if
+// includeDeleted is set.
+func (s *Service) findVisible(ctx context.Context, id string, includeDeleted bool) (*Receipt, error) {
+	receipt, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.DeletedAt != nil && !includeDeleted {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
+
+	return receipt, nil
+}
+
+// amend replaces current with the receipt dto describes, keeping its ID and
+// creation time.
+func (s *Service) amend(ctx context.Context, current *Receipt, dto ReceiptDTO) (*Receipt, error) {
+	if current.DeletedAt != nil {
+		return nil, &errs.Error{
+			Code:    errs.ECONFLICT,
+			Message: "Deleted receipts can't be amended, restore the receipt first",
+		}
+	}
+
+	rec, err := newReceipt(dto, s.policy, s.classifier)
+	if err != nil {
+		return nil, err
+	}
+	rec.ID = current.ID
+	rec.CreatedAt = current.CreatedAt
+	for i := range rec.Items {
+		rec.Items[i].ReceiptID = rec.ID
+	}
+
+	// An amendment that leaves the content alone finds the receipt itself.
+	originalID, err := s.findOriginal(ctx, rec.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if originalID == rec.ID {
+		originalID = ""
+	}
+
+	breakdown, err := s.score(ctx, rec, s.rulesets.Current())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.markDuplicate(rec, originalID, &breakdown)
+	if err != nil {
+		return nil, err
+	}
+	rec.Points = &breakdown.Points
+	rec.RulesetVersion = breakdown.RulesetVersion
+	rec.Campaigns = breakdown.Campaigns
+
+	err = s.repository.Update(ctx, rec)
+	if errors.Is(err, ErrOriginalExists) {
+		err = s.refindOriginal(ctx, rec, &breakdown)
+		if err != nil {
+			return nil, err
+		}
+		err = s.repository.Update(ctx, rec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.purgeCache(ctx, rec.ID)
+
+	return rec, nil
+}
+
+// purgeCache drops the cached copies of the receipt and the receipt list
+// pages, which may hold it.
+func (s *Service) purgeCache(ctx context.Context, id string) {
+	_ = s.cache.DeleteReceiptById(ctx, id)
+	_ = s.cache.DeletePaginatedReceipts(ctx)
+}
+
+// recordedScore returns the points stored with the receipt. Receipts created
+// before points were recorded are scored with the ruleset version they carry.
+func (s *Service) recordedScore(receipt *Receipt) (Score, error) {
+	if receipt.Points != nil {
+		return Score{Points: *receipt.Points, RulesetVersion: receipt.RulesetVersion}, nil
+	}
+
+	ruleset, err := s.rulesets.Get(receipt.RulesetVersion)
+	if err != nil {
+		return Score{}, err
+	}
+
+	return Score{Points: ruleset.Score(*receipt), RulesetVersion: ruleset.Version}, nil
+}
+
+// newReceipt validates the DTO and builds the receipt it describes, assigning
+// new IDs to the receipt and its items. Items without a category are
+// classified with classifier.
+func newReceipt(dto ReceiptDTO, policy ValidationPolicy, classifier Classifier) (*Receipt, error) {
+	isValid, errors := dto.IsValid(policy)
+	if !isValid {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rec := &Receipt{
+		ID:        uuid.New().String(),
+		Retailer:  dto.Retailer,
+		Total:     dto.Total,
+		Items:     make([]Item, 0, len(dto.Items)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	loc, err := LoadTimezone(dto.Timezone)
+	if err != nil {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: map[string][]string{
+				"timezone": {"invalid timezone, it should be an IANA name or a UTC offset like -05:00"},
+			},
+		}
+	}
+	rec.Timezone = loc.String()
+
+	purchasedAt, err := time.ParseInLocation(
+		"2006-01-02 15:04",
+		dto.PurchaseDate+" "+dto.PurchaseTime,
+		loc,
+	)
+	if err != nil {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: map[string][]string{
+				"purchaseDate": {"invalid format, it should be YYYY-MM-DD"},
+			},
+		}
+	}
+	rec.PurchasedAt = purchasedAt
+
+	for _, itemDto := range dto.Items {
+		item := Item{
+			ID:               uuid.New().String(),
+			ShortDescription: itemDto.ShortDescription,
+			Price:            itemDto.Price,
+			Quantity:         max(itemDto.Quantity, 1),
+			UnitPrice:        itemDto.UnitPrice,
+			Category:         itemDto.Category,
+		}
+		if item.UnitPrice == 0 {
+			item.UnitPrice = item.Price
+		}
+		if item.Category == "" {
+			item.Category = classifier.Classify(item.ShortDescription)
+		}
+		rec.Items = append(rec.Items, item)
+	}
+	rec.Categories = SummarizeCategories(rec.Items)
+
+	rec.Adjustments = make([]Adjustment, len(dto.Adjustments))
+	for i, adjustment := range dto.Adjustments {
+		rec.Adjustments[i] = Adjustment(adjustment)
+	}
+	rec.Fingerprint = rec.ContentFingerprint()
+
+	return rec, nil
+}
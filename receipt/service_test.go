@@ -0,0 +1,205 @@
+package receipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+func TestGetById(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	legacy := &Receipt{
+		ID:             "6b3d8a4e-5f0c-4b9b-9a57-2d0f1c7e8a10",
+		Retailer:       "Target",
+		PurchasedAt:    time.Date(2022, time.January, 2, 13, 13, 0, 0, time.UTC),
+		Total:          money("1.25"),
+		Items:          []Item{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+		RulesetVersion: DefaultRuleset().Version,
+	}
+	repository := &batchRepository{created: []*Receipt{legacy}}
+	service := NewService(repository, campaignsStub{}, nopCache{}, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	rec, err := service.GetById(context.Background(), legacy.ID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Points == nil || *rec.Points != 31 {
+		t.Errorf("expected receipts without recorded points to be scored, 31 points. got %v", rec.Points)
+	}
+
+	_, err = service.GetById(context.Background(), "not-a-uuid", false)
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected a not found error. got %v", err)
+	}
+}
+
+// memoryCache caches receipts and scores in maps, pages aren't cached.
+type memoryCache struct {
+	nopCache
+	receipts map[string]*Receipt
+	scores   map[string]Score
+}
+
+func (c *memoryCache) GetScoreById(ctx context.Context, id string) (Score, error) {
+	score, ok := c.scores[id]
+	if !ok {
+		return Score{}, &errs.Error{Code: errs.ENOTFOUND, Message: "Score not found in cache"}
+	}
+
+	return score, nil
+}
+
+func (c *memoryCache) SetScoreById(ctx context.Context, id string, score Score, exp time.Duration) error {
+	c.scores[id] = score
+	return nil
+}
+
+func (c *memoryCache) GetReceiptById(ctx context.Context, id string) (*Receipt, error) {
+	rec, ok := c.receipts[id]
+	if !ok {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found in cache"}
+	}
+
+	return rec, nil
+}
+
+func (c *memoryCache) SetReceiptById(ctx context.Context, receipt *Receipt, exp time.Duration) error {
+	c.receipts[receipt.ID] = receipt
+	return nil
+}
+
+func (c *memoryCache) DeleteReceiptById(ctx context.Context, id string) error {
+	delete(c.receipts, id)
+	return nil
+}
+
+func TestProcessCachesScore(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cache := &memoryCache{receipts: map[string]*Receipt{}, scores: map[string]Score{}}
+	service := NewService(&batchRepository{}, campaignsStub{}, cache, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	rec, err := service.Process(context.Background(), dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if score, ok := cache.scores[rec.ID]; !ok || score.Points != *rec.Points {
+		t.Errorf("expected %d points cached when Process returns. got %v", *rec.Points, cache.scores)
+	}
+
+	dto.Retailer = "Walgreens"
+	results, err := service.ProcessBatch(context.Background(), []ReceiptDTO{dto})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if score, ok := cache.scores[results[0].ID]; !ok || score.Points != *results[0].Points {
+		t.Errorf("expected %d points cached when ProcessBatch returns. got %v", *results[0].Points, cache.scores)
+	}
+}
+
+func TestScoreDoesNotPersist(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repository := &batchRepository{}
+	cache := &memoryCache{receipts: map[string]*Receipt{}, scores: map[string]Score{}}
+	service := NewService(repository, campaignsStub{}, cache, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	breakdown, err := service.Score(context.Background(), dto, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repository.created) != 0 || len(cache.scores) != 0 {
+		t.Fatalf("expected nothing stored or cached. got %d receipts, %d scores", len(repository.created), len(cache.scores))
+	}
+
+	rec, err := service.Process(context.Background(), dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breakdown.Points != *rec.Points || breakdown.RulesetVersion != rec.RulesetVersion {
+		t.Errorf("expected %d points with %s. got %d with %s", *rec.Points, rec.RulesetVersion, breakdown.Points, breakdown.RulesetVersion)
+	}
+
+	_, err = service.Score(context.Background(), dto, "unknown")
+	if errs.ErrorCode(err) != errs.ENOTFOUND {
+		t.Errorf("expected %s for an unknown ruleset version. got %v", errs.ENOTFOUND, err)
+	}
+
+	dto.Retailer = ""
+	_, err = service.Score(context.Background(), dto, "")
+	if errs.ErrorCode(err) != errs.EINVALID {
+		t.Errorf("expected %s for an invalid receipt. got %v", errs.EINVALID, err)
+	}
+}
+
+func TestGetByIdCachesBeforeAmend(t *testing.T) {
+	rulesets, err := NewRulesets(DefaultRuleset())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repository := &batchRepository{}
+	cache := &memoryCache{receipts: map[string]*Receipt{}, scores: map[string]Score{}}
+	service := NewService(repository, campaignsStub{}, cache, rulesets, DefaultClassifier(), DuplicateReject, DefaultValidationPolicy())
+
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Pepsi - 12-oz", Price: money("1.25")}},
+	}
+	original, err := service.Process(context.Background(), dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = service.GetById(context.Background(), original.ID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.receipts[original.ID]; !ok {
+		t.Fatalf("expected the receipt to be cached when GetById returns")
+	}
+
+	dto.Total = money("3.00")
+	dto.Items[0].Price = money("3.00")
+	_, err = service.Update(context.Background(), original.ID, dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec, err := service.GetById(context.Background(), original.ID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Total != money("3.00") {
+		t.Errorf("expected the amended total 3.00. got %s", rec.Total)
+	}
+}
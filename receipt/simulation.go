@@ -0,0 +1,196 @@
+package receipt
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+const (
+	defaultSimulationTop     = 10
+	defaultSimulationBuckets = 20
+)
+
+type SimulationOptions struct {
+	// Top is the number of most changed receipts to report.
+	Top int
+
+	// Buckets is the number of histogram buckets.
+	Buckets int
+}
+
+// SimulationReport compares the rules points of every stored receipt under the
+// current ruleset and a candidate one. Campaign points are left out on both
+// sides since they don't depend on the ruleset.
+type SimulationReport struct {
+	CurrentVersion   string            `json:"currentVersion"`
+	CandidateVersion string            `json:"candidateVersion"`
+	Receipts         int               `json:"receipts"`
+	Changed          int               `json:"changed"`
+	Current          PointsStats       `json:"current"`
+	Candidate        PointsStats       `json:"candidate"`
+	Histogram        []HistogramBucket `json:"histogram"`
+	TopChanges       []PointsChange    `json:"topChanges"`
+}
+
+type PointsStats struct {
+	Mean float64 `json:"mean"`
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	P50  int     `json:"p50"`
+	P90  int     `json:"p90"`
+	P99  int     `json:"p99"`
+}
+
+// HistogramBucket counts the receipts whose points fall in [From, To).
+type HistogramBucket struct {
+	From      int `json:"from"`
+	To        int `json:"to"`
+	Current   int `json:"current"`
+	Candidate int `json:"candidate"`
+}
+
+type PointsChange struct {
+	ReceiptID string `json:"receiptID"`
+	Current   int    `json:"current"`
+	Candidate int    `json:"candidate"`
+	Delta     int    `json:"delta"`
+}
+
+// Simulate streams every stored receipt through both rulesets, soft deleted
+// receipts are skipped. Nothing is written.
+func Simulate(
+	ctx context.Context,
+	repository ReceiptRepository,
+	current, candidate Ruleset,
+	opts SimulationOptions,
+) (SimulationReport, error) {
+	if opts.Top <= 0 {
+		opts.Top = defaultSimulationTop
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = defaultSimulationBuckets
+	}
+
+	report := SimulationReport{
+		CurrentVersion:   current.Version,
+		CandidateVersion: candidate.Version,
+		Histogram:        []HistogramBucket{},
+		TopChanges:       []PointsChange{},
+	}
+	currentPoints := []int{}
+	candidatePoints := []int{}
+
+	err := repository.Each(ctx, func(rec *Receipt) error {
+		if rec.DeletedAt != nil {
+			return ctx.Err()
+		}
+
+		change := PointsChange{
+			ReceiptID: rec.ID,
+			Current:   current.Score(*rec),
+			Candidate: candidate.Score(*rec),
+		}
+		change.Delta = change.Candidate - change.Current
+
+		currentPoints = append(currentPoints, change.Current)
+		candidatePoints = append(candidatePoints, change.Candidate)
+
+		if change.Delta != 0 {
+			report.Changed++
+			report.TopChanges = keepTopChanges(report.TopChanges, change, opts.Top)
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return SimulationReport{}, err
+	}
+
+	report.Receipts = len(currentPoints)
+	if report.Receipts == 0 {
+		return report, nil
+	}
+
+	slices.Sort(currentPoints)
+	slices.Sort(candidatePoints)
+	report.Current = pointsStats(currentPoints)
+	report.Candidate = pointsStats(candidatePoints)
+	report.Histogram = histogram(currentPoints, candidatePoints, opts.Buckets)
+
+	return report, nil
+}
+
+// keepTopChanges inserts change in changes, sorted by absolute delta, and
+// keeps at most n of them.
+func keepTopChanges(changes []PointsChange, change PointsChange, n int) []PointsChange {
+	i := sort.Search(len(changes), func(i int) bool {
+		return absInt(changes[i].Delta) < absInt(change.Delta)
+	})
+	if i >= n {
+		return changes
+	}
+
+	changes = slices.Insert(changes, i, change)
+	if len(changes) > n {
+		changes = changes[:n]
+	}
+
+	return changes
+}
+
+// pointsStats summarizes sorted points.
+func pointsStats(sorted []int) PointsStats {
+	sum := 0
+	for _, p := range sorted {
+		sum += p
+	}
+
+	return PointsStats{
+		Mean: float64(sum) / float64(len(sorted)),
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+		P99:  percentile(sorted, 99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted points.
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// histogram splits [min, max] of both sorted series into equal width buckets.
+func histogram(current, candidate []int, buckets int) []HistogramBucket {
+	lo := min(current[0], candidate[0])
+	hi := max(current[len(current)-1], candidate[len(candidate)-1])
+	width := max(1, (hi-lo+buckets)/buckets)
+
+	result := make([]HistogramBucket, 0, buckets)
+	for from := lo; from <= hi; from += width {
+		result = append(result, HistogramBucket{From: from, To: from + width})
+	}
+
+	for _, p := range current {
+		result[(p-lo)/width].Current++
+	}
+	for _, p := range candidate {
+		result[(p-lo)/width].Candidate++
+	}
+
+	return result
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,111 @@
+package receipt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type memoryRepository struct {
+	ReceiptRepository
+	receipts []Receipt
+}
+
+func (m memoryRepository) Each(ctx context.Context, fn func(*Receipt) error) error {
+	for i := range m.receipts {
+		if err := fn(&m.receipts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestSimulate(t *testing.T) {
+	receipts := make([]Receipt, 0, 11)
+	for day := 1; day <= 10; day++ {
+		receipts = append(receipts, Receipt{
+			ID:          string(rune('a' + day)),
+			Retailer:    "Target",
+			PurchasedAt: time.Date(2022, time.January, day, 10, 0, 0, 0, time.UTC),
+			Items:       []Item{{ShortDescription: "Item", Price: money("1.00")}},
+			Total:       money("1.00"),
+		})
+	}
+
+	// A soft deleted receipt on an odd day, left out of the report.
+	deletedAt := time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)
+	receipts = append(receipts, Receipt{
+		ID:          "deleted",
+		Retailer:    "Target",
+		PurchasedAt: time.Date(2022, time.January, 11, 10, 0, 0, 0, time.UTC),
+		Items:       []Item{{ShortDescription: "Item", Price: money("1.00")}},
+		Total:       money("1.00"),
+		DeletedAt:   &deletedAt,
+	})
+
+	current := Ruleset{
+		Version: "current",
+		Rules:   []Rule{{Name: "odd day", Kind: RuleOddPurchaseDay, Points: 6}},
+	}
+	candidate := Ruleset{
+		Version: "candidate",
+		Rules:   []Rule{{Name: "odd day", Kind: RuleOddPurchaseDay, Points: 10}},
+	}
+
+	report, err := Simulate(
+		context.Background(),
+		memoryRepository{receipts: receipts},
+		current,
+		candidate,
+		SimulationOptions{Top: 3, Buckets: 5},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Receipts != 10 {
+		t.Errorf("expected 10 receipts. got %d", report.Receipts)
+	}
+	if report.Changed != 5 {
+		t.Errorf("expected 5 changed receipts. got %d", report.Changed)
+	}
+	if report.Current.Mean != 3 || report.Candidate.Mean != 5 {
+		t.Errorf("expected means 3 and 5. got %v and %v", report.Current.Mean, report.Candidate.Mean)
+	}
+	if report.Candidate.P50 != 0 || report.Candidate.P90 != 10 || report.Candidate.Max != 10 {
+		t.Errorf("unexpected candidate stats %+v", report.Candidate)
+	}
+	if len(report.TopChanges) != 3 || report.TopChanges[0].Delta != 4 {
+		t.Errorf("expected 3 top changes with delta 4. got %+v", report.TopChanges)
+	}
+
+	currentTotal, candidateTotal := 0, 0
+	for _, bucket := range report.Histogram {
+		currentTotal += bucket.Current
+		candidateTotal += bucket.Candidate
+	}
+	if currentTotal != 10 || candidateTotal != 10 {
+		t.Errorf("expected histogram to count 10 receipts per side. got %d and %d", currentTotal, candidateTotal)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p    int
+		want int
+	}{
+		{50, 5},
+		{90, 9},
+		{99, 10},
+		{0, 1},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%d) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package receipt
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+// LoadTimezone resolves an IANA timezone name, such as America/New_York, or a
+// UTC offset, such as -05:00. An empty name is UTC.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	// Local depends on the server, it's never what the receipt means.
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+
+	if t, err := time.Parse("Z07:00", name); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+
+	return time.LoadLocation(name)
+}
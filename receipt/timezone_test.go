@@ -0,0 +1,130 @@
+package receipt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantOffset int
+		wantErr    bool
+	}{
+		{"", 0, false},
+		{"UTC", 0, false},
+		{"Z", 0, false},
+		{"-05:00", -5 * 60 * 60, false},
+		{"+05:30", (5*60 + 30) * 60, false},
+		{"Asia/Tokyo", 9 * 60 * 60, false},
+		{"Local", 0, true},
+		{"Mars/Olympus_Mons", 0, true},
+		{"+5", 0, true},
+	}
+
+	at := time.Date(2022, time.January, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		loc, err := LoadTimezone(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadTimezone(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, offset := at.In(loc).Zone(); offset != tt.wantOffset {
+			t.Errorf("LoadTimezone(%q) offset = %d, want %d", tt.name, offset, tt.wantOffset)
+		}
+	}
+}
+
+func TestNewReceiptLocalTime(t *testing.T) {
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-06-30",
+		PurchaseTime: "14:30",
+		Timezone:     "America/New_York",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Gum", Price: money("1.25")}},
+	}
+
+	rec, err := newReceipt(dto, DefaultValidationPolicy(), DefaultClassifier())
+	if err != nil {
+		t.Fatalf("newReceipt() error = %v", err)
+	}
+
+	expectedInstant := time.Date(2022, time.June, 30, 18, 30, 0, 0, time.UTC)
+	if !rec.PurchasedAt.Equal(expectedInstant) {
+		t.Errorf("expected purchasedAt %s. got %s", expectedInstant, rec.PurchasedAt.UTC())
+	}
+	if rec.Timezone != "America/New_York" {
+		t.Errorf("expected timezone America/New_York. got %s", rec.Timezone)
+	}
+
+	// 14:30 local is 18:30 UTC, the rules look at the local wall-clock.
+	if points := rec.GetPointsTimeOfPurchase(); points != 10 {
+		t.Errorf("expected 10 time of purchase points. got %d", points)
+	}
+
+	// 23:30 on June 30th in Los Angeles is already July 1st in UTC.
+	dto.PurchaseTime = "23:30"
+	dto.Timezone = "-07:00"
+	rec, err = newReceipt(dto, DefaultValidationPolicy(), DefaultClassifier())
+	if err != nil {
+		t.Fatalf("newReceipt() error = %v", err)
+	}
+	if points := rec.GetPointsPurchaseDayIsOdd(); points != 0 {
+		t.Errorf("expected 0 odd day points. got %d", points)
+	}
+}
+
+func TestReceiptJSONPurchaseDateTime(t *testing.T) {
+	dto := ReceiptDTO{
+		Retailer:     "Target",
+		PurchaseDate: "2022-06-30",
+		PurchaseTime: "23:30",
+		Timezone:     "-07:00",
+		Total:        money("1.25"),
+		Items:        []ItemDTO{{ShortDescription: "Gum", Price: money("1.25")}},
+	}
+
+	rec, err := newReceipt(dto, DefaultValidationPolicy(), DefaultClassifier())
+	if err != nil {
+		t.Fatalf("newReceipt() error = %v", err)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fields map[string]any
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The local date and time, not the UTC ones, next to the new fields.
+	expected := map[string]any{
+		"purchaseDate": "2022-06-30",
+		"purchaseTime": "23:30",
+		"purchasedAt":  "2022-06-30T23:30:00-07:00",
+		"timezone":     "-07:00",
+		"retailer":     "Target",
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("expected %s %v. got %v", key, value, fields[key])
+		}
+	}
+
+	var decoded Receipt
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.PurchasedAt.Equal(rec.PurchasedAt) || decoded.Retailer != rec.Retailer {
+		t.Errorf("expected the receipt to decode back. got %+v", decoded)
+	}
+}
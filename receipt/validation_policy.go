@@ -0,0 +1,155 @@
+package receipt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CharacterClass is a kind of character retailer names may be allowed to
+// contain.
+type CharacterClass string
+
+const (
+	// CharactersLetters are letters of any script, with their accents.
+	CharactersLetters     CharacterClass = "letters"
+	CharactersDigits      CharacterClass = "digits"
+	CharactersSpaces      CharacterClass = "spaces" // any white space, tabs included
+	CharactersPunctuation CharacterClass = "punctuation"
+	CharactersSymbols     CharacterClass = "symbols"
+)
+
+var characterClasses = []CharacterClass{
+	CharactersLetters,
+	CharactersDigits,
+	CharactersSpaces,
+	CharactersPunctuation,
+	CharactersSymbols,
+}
+
+func (c CharacterClass) contains(r rune) bool {
+	switch c {
+	case CharactersLetters:
+		return unicode.IsLetter(r) || unicode.IsMark(r)
+	case CharactersDigits:
+		return unicode.IsDigit(r)
+	case CharactersSpaces:
+		return unicode.IsSpace(r)
+	case CharactersPunctuation:
+		return unicode.IsPunct(r)
+	case CharactersSymbols:
+		return unicode.IsSymbol(r)
+	}
+
+	return false
+}
+
+// ParseCharacterClasses parses character classes separated by spaces.
+func ParseCharacterClasses(s string) ([]CharacterClass, error) {
+	classes := []CharacterClass{}
+	for _, name := range strings.Fields(s) {
+		class := CharacterClass(name)
+		if !slices.Contains(characterClasses, class) {
+			return nil, fmt.Errorf(
+				"unknown character class %q, it should be one of: %s",
+				name,
+				characterClassNames(characterClasses),
+			)
+		}
+		if !slices.Contains(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("character classes cannot be empty")
+	}
+
+	return classes, nil
+}
+
+func characterClassNames(classes []CharacterClass) string {
+	names := make([]string, len(classes))
+	for i, class := range classes {
+		names[i] = string(class)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// ValidationPolicy holds the limits receipts and receipt listings are
+// validated against.
+type ValidationPolicy struct {
+	RetailerMaxLength int
+
+	// RetailerCharacters are the classes of the characters retailer names
+	// may contain.
+	RetailerCharacters []CharacterClass
+
+	// DescriptionMaxLength applies to item short descriptions and
+	// adjustment descriptions.
+	DescriptionMaxLength int
+
+	MaxItems int
+
+	// AllowFuturePurchases accepts receipts purchased later than the time
+	// they are validated, in the receipt's timezone.
+	AllowFuturePurchases bool
+
+	// MaxPurchaseAgeDays refuses receipts purchased more than that many days
+	// ago, zero means any age.
+	MaxPurchaseAgeDays int
+
+	// MaxPage and MaxLimit bound the page and page size of receipt listings.
+	MaxPage  int
+	MaxLimit int
+}
+
+func DefaultValidationPolicy() ValidationPolicy {
+	return ValidationPolicy{
+		RetailerMaxLength:    50,
+		RetailerCharacters:   characterClasses,
+		DescriptionMaxLength: 100,
+		MaxItems:             500,
+		AllowFuturePurchases: false,
+		MaxPurchaseAgeDays:   0,
+		MaxPage:              10_000_000,
+		MaxLimit:             100,
+	}
+}
+
+// Validate reports the first limit of the policy that allows nothing. A zero
+// MaxPurchaseAgeDays means any age, every other limit must be at least 1.
+func (p ValidationPolicy) Validate() error {
+	limits := []struct {
+		name  string
+		value int
+		min   int
+	}{
+		{"retailer max length", p.RetailerMaxLength, 1},
+		{"description max length", p.DescriptionMaxLength, 1},
+		{"max items", p.MaxItems, 1},
+		{"max purchase age days", p.MaxPurchaseAgeDays, 0},
+		{"max page", p.MaxPage, 1},
+		{"max limit", p.MaxLimit, 1},
+	}
+	for _, limit := range limits {
+		if limit.value < limit.min {
+			return fmt.Errorf("%s: it should be at least %d, got %d", limit.name, limit.min, limit.value)
+		}
+	}
+
+	return nil
+}
+
+// allowsRetailerRune reports whether retailer names may contain r.
+func (p ValidationPolicy) allowsRetailerRune(r rune) bool {
+	for _, class := range p.RetailerCharacters {
+		if class.contains(r) {
+			return true
+		}
+	}
+
+	return false
+}
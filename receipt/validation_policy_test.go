@@ -0,0 +1,134 @@
+package receipt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidationPolicy(t *testing.T) {
+	now := time.Now().UTC()
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	lastMonth := now.AddDate(0, 0, -30).Format("2006-01-02")
+
+	strict := DefaultValidationPolicy()
+	strict.RetailerMaxLength = 10
+	strict.RetailerCharacters = []CharacterClass{CharactersLetters, CharactersSpaces}
+	strict.DescriptionMaxLength = 5
+	strict.MaxItems = 1
+	strict.MaxPurchaseAgeDays = 7
+
+	lenient := DefaultValidationPolicy()
+	lenient.AllowFuturePurchases = true
+
+	tests := []struct {
+		name    string
+		policy  ValidationPolicy
+		mutate  func(dto *ReceiptDTO)
+		wantKey string
+	}{
+		{"default", DefaultValidationPolicy(), func(dto *ReceiptDTO) {}, ""},
+		{"accented retailer", strict, func(dto *ReceiptDTO) { dto.Retailer = "Cafe\u0301 Ña" }, ""},
+		{"retailer too long", strict, func(dto *ReceiptDTO) { dto.Retailer = "Target Store" }, "retailer"},
+		{"retailer with digits", strict, func(dto *ReceiptDTO) { dto.Retailer = "7 Eleven" }, "retailer"},
+		{"retailer with tab", strict, func(dto *ReceiptDTO) { dto.Retailer = "Big\tStore" }, ""},
+		{"retailer with control character", DefaultValidationPolicy(), func(dto *ReceiptDTO) { dto.Retailer = "Target\x07" }, "retailer"},
+		{"description too long", strict, func(dto *ReceiptDTO) { dto.Items[0].ShortDescription = "Gatorade" }, "items[0].shortDescription"},
+		{"too many items", strict, func(dto *ReceiptDTO) { dto.Items = append(dto.Items, dto.Items[0]) }, "items"},
+		{"future purchase", DefaultValidationPolicy(), func(dto *ReceiptDTO) { dto.PurchaseDate = tomorrow }, "purchaseDate"},
+		{"future purchase allowed", lenient, func(dto *ReceiptDTO) { dto.PurchaseDate = tomorrow }, ""},
+		{"old purchase", strict, func(dto *ReceiptDTO) { dto.PurchaseDate = lastMonth }, "purchaseDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := ReceiptDTO{
+				Retailer:     "Target",
+				PurchaseDate: now.AddDate(0, 0, -1).Format("2006-01-02"),
+				PurchaseTime: "13:01",
+				Total:        money("1.25"),
+				Items:        []ItemDTO{{ShortDescription: "Pepsi", Price: money("1.25")}},
+			}
+			tt.mutate(&dto)
+			dto.Total = 0
+			for _, item := range dto.Items {
+				dto.Total += item.Price
+			}
+
+			isValid, errors := dto.IsValid(tt.policy)
+			if tt.wantKey == "" {
+				if !isValid {
+					t.Errorf("expected a valid receipt. got %v", errors)
+				}
+				return
+			}
+			if len(errors) != 1 || len(errors[tt.wantKey]) != 1 {
+				t.Errorf("expected one error for %s. got %v", tt.wantKey, errors)
+			}
+		})
+	}
+}
+
+func TestFiltersPolicy(t *testing.T) {
+	policy := DefaultValidationPolicy()
+	policy.MaxLimit = 20
+
+	filters := NewFilters("id")
+	filters.Page = 1
+	filters.Sort = "id"
+
+	filters.Limit = 20
+	if isValid, errors := filters.IsValid(policy); !isValid {
+		t.Errorf("expected a limit of 20 to be valid. got %v", errors)
+	}
+
+	filters.Limit = 21
+	if isValid, _ := filters.IsValid(policy); isValid {
+		t.Errorf("expected a limit of 21 to be invalid")
+	}
+}
+
+func TestValidationPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(policy *ValidationPolicy)
+		wantErr bool
+	}{
+		{"default", func(policy *ValidationPolicy) {}, false},
+		{"any purchase age", func(policy *ValidationPolicy) { policy.MaxPurchaseAgeDays = 0 }, false},
+		{"negative purchase age", func(policy *ValidationPolicy) { policy.MaxPurchaseAgeDays = -1 }, true},
+		{"zero retailer length", func(policy *ValidationPolicy) { policy.RetailerMaxLength = 0 }, true},
+		{"zero description length", func(policy *ValidationPolicy) { policy.DescriptionMaxLength = 0 }, true},
+		{"zero items", func(policy *ValidationPolicy) { policy.MaxItems = 0 }, true},
+		{"zero page", func(policy *ValidationPolicy) { policy.MaxPage = 0 }, true},
+		{"zero limit", func(policy *ValidationPolicy) { policy.MaxLimit = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultValidationPolicy()
+			tt.mutate(&policy)
+
+			err := policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected an error %t. got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseCharacterClasses(t *testing.T) {
+	classes, err := ParseCharacterClasses(" letters  digits letters ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(classes) != 2 || classes[0] != CharactersLetters || classes[1] != CharactersDigits {
+		t.Errorf("expected letters and digits. got %v", classes)
+	}
+
+	for _, s := range []string{"", "letters emoji"} {
+		_, err := ParseCharacterClasses(s)
+		if err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package receipt
 
 import (
-	"math"
 	"unicode"
 )
 
@@ -9,12 +8,12 @@ func isAlphanumeric(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsDigit(r)
 }
 
-func hasZeroDecimal(f float64) bool {
-	return math.Mod(f, 1.0) == 0
+func hasZeroDecimal(m Money) bool {
+	return m.Cents()%100 == 0
 }
 
-func xIsMultipleOfy(x, y float64) bool {
-	return math.Mod(x, y) == 0.0
+func xIsMultipleOfy(x, y int64) bool {
+	return x%y == 0
 }
 
 func isOdd(n int) bool {
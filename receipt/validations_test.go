@@ -30,45 +30,45 @@ func TestIsAlphanumeric(t *testing.T) {
 
 func TestHasZeroDecimal(t *testing.T) {
 	tests := []struct {
-		input float64
+		input Money
 		want  bool
 	}{
-		{0.0, true},
-		{1.0, true},
-		{100.0, true},
-		{0.5, false},
-		{1.99, false},
-		{3.14159, false},
+		{0, true},
+		{100, true},
+		{10000, true},
+		{50, false},
+		{199, false},
+		{314, false},
+		{-200, true},
 	}
 
 	for _, tt := range tests {
 		got := hasZeroDecimal(tt.input)
 		if got != tt.want {
-			t.Errorf("hasZeroDecimal(%f) = %v, want %v", tt.input, got, tt.want)
+			t.Errorf("hasZeroDecimal(%s) = %v, want %v", tt.input, got, tt.want)
 		}
 	}
 }
 
 func TestXIsMultipleOfY(t *testing.T) {
 	tests := []struct {
-		x    float64
-		y    float64
+		x    int64
+		y    int64
 		want bool
 	}{
-		{10.0, 5.0, true},
-		{9.0, 3.0, true},
-		{0.25, 0.25, true},
-		{1.0, 0.25, true},
-		{1.0, 0.25, true},
-		{7.0, 3.0, false},
-		{1.0, 0.30, false},
-		{0.0, 1.0, true},
+		{10, 5, true},
+		{9, 3, true},
+		{25, 25, true},
+		{100, 25, true},
+		{7, 3, false},
+		{100, 30, false},
+		{0, 100, true},
 	}
 
 	for _, tt := range tests {
 		got := xIsMultipleOfy(tt.x, tt.y)
 		if got != tt.want {
-			t.Errorf("xIsMultipleOfy(%f, %f) = %v, want %v", tt.x, tt.y, got, tt.want)
+			t.Errorf("xIsMultipleOfy(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
 		}
 	}
 }
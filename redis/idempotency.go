@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// idempotencyLockTTL bounds how long a key stays claimed by a request that
+// never completes, e.g. because the server crashed while processing it.
+const idempotencyLockTTL = time.Minute
+
+// StoredResponse is the response recorded for an idempotency key. Status is
+// zero while the first request with the key is still being processed.
+type StoredResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyStore remembers the responses to requests sent with an
+// idempotency key so retries are answered without processing them again.
+type IdempotencyStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewIdempotencyStore(client *redis.Client, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{client, ttl}
+}
+
+func idempotencyKey(key string) string {
+	return "idempotency:" + key
+}
+
+// Begin claims key for a request with the given fingerprint. It returns nil
+// when the key was free, the caller must then Complete or Abandon it.
+// Otherwise it returns what is stored for the key.
+func (s *IdempotencyStore) Begin(ctx context.Context, key, fingerprint string) (*StoredResponse, error) {
+	pending, err := json.Marshal(StoredResponse{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	// The key may expire between SetNX and Get, claiming it is then tried
+	// again.
+	for range 2 {
+		claimed, err := s.client.SetNX(ctx, idempotencyKey(key), pending, idempotencyLockTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return nil, nil
+		}
+
+		val, err := s.client.Get(ctx, idempotencyKey(key)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var stored StoredResponse
+		err = json.Unmarshal(val, &stored)
+		if err != nil {
+			return nil, &errs.Error{
+				Code:    errs.EINTERNAL,
+				Message: "Error unmarshaling idempotent response from redis",
+			}
+		}
+
+		return &stored, nil
+	}
+
+	return nil, &errs.Error{
+		Code:    errs.ECONFLICT,
+		Message: "A request with this Idempotency-Key is being processed",
+	}
+}
+
+// Complete stores the response for key, replacing the claim made by Begin.
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, response StoredResponse) error {
+	b, err := json.Marshal(response)
+	if err != nil {
+		return &errs.Error{
+			Code:    errs.EINTERNAL,
+			Message: "Error marshaling idempotent response before storing on redis",
+		}
+	}
+
+	return s.client.Set(ctx, idempotencyKey(key), b, s.ttl).Err()
+}
+
+// Abandon releases key so the request can be retried.
+func (s *IdempotencyStore) Abandon(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyKey(key)).Err()
+}
@@ -17,35 +17,124 @@ type ReceiptCache struct {
 	duration    time.Duration
 }
 
-func (c ReceiptCache) GetPointsById(ctx context.Context, id string) (int, error) {
-	points, err := c.redisClient.Get(ctx, id).Int()
+func scoreKey(id string) string {
+	return "receipt:" + id + ":score"
+}
+
+func (c ReceiptCache) GetScoreById(ctx context.Context, id string) (receipt.Score, error) {
+	val, err := c.redisClient.Get(ctx, scoreKey(id)).Result()
 	if err != nil {
 		switch {
 		case errors.Is(err, redis.Nil):
-			return 0, &errs.Error{
+			return receipt.Score{}, &errs.Error{
 				Code:    errs.ENOTFOUND,
 				Message: "Receipt's points not found in cache",
 			}
 		default:
-			return 0, err
+			return receipt.Score{}, err
+		}
+	}
+
+	var score receipt.Score
+	err = json.Unmarshal([]byte(val), &score)
+	if err != nil {
+		return receipt.Score{}, &errs.Error{
+			Code:    errs.EINTERNAL,
+			Message: "Error unmarshaling receipt's points from redis",
 		}
 	}
 
-	return points, nil
+	return score, nil
 }
 
-func (c ReceiptCache) SetPointsById(
+func (c ReceiptCache) SetScoreById(
 	ctx context.Context,
 	id string,
-	points int,
+	score receipt.Score,
 	exp time.Duration,
 ) error {
-	return c.redisClient.Set(
-		ctx,
-		id,
-		points,
-		exp,
-	).Err()
+	b, err := json.Marshal(score)
+	if err != nil {
+		return &errs.Error{
+			Code:    errs.EINTERNAL,
+			Message: "Error marshaling receipt's points before storing on redis",
+		}
+	}
+
+	return c.redisClient.Set(ctx, scoreKey(id), b, exp).Err()
+}
+
+func receiptKey(id string) string {
+	return "receipt:" + id
+}
+
+// GetReceiptById returns a cached receipt, its Fingerprint isn't cached.
+func (c ReceiptCache) GetReceiptById(ctx context.Context, id string) (*receipt.Receipt, error) {
+	val, err := c.redisClient.Get(ctx, receiptKey(id)).Result()
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			return nil, &errs.Error{
+				Code:    errs.ENOTFOUND,
+				Message: "Receipt not found in cache",
+			}
+		default:
+			return nil, err
+		}
+	}
+
+	var rec receipt.Receipt
+	err = json.Unmarshal([]byte(val), &rec)
+	if err != nil {
+		return nil, &errs.Error{
+			Code:    errs.EINTERNAL,
+			Message: "Error unmarshaling receipt from redis",
+		}
+	}
+
+	return &rec, nil
+}
+
+func (c ReceiptCache) SetReceiptById(
+	ctx context.Context,
+	rec *receipt.Receipt,
+	exp time.Duration,
+) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return &errs.Error{
+			Code:    errs.EINTERNAL,
+			Message: "Error marshaling receipt before storing on redis",
+		}
+	}
+
+	return c.redisClient.Set(ctx, receiptKey(rec.ID), b, exp).Err()
+}
+
+// DeleteReceiptById drops the cached receipt and its points.
+func (c ReceiptCache) DeleteReceiptById(ctx context.Context, id string) error {
+	return c.redisClient.Del(ctx, receiptKey(id), scoreKey(id)).Err()
+}
+
+// DeletePaginatedReceipts drops every cached page of the receipt list.
+func (c ReceiptCache) DeletePaginatedReceipts(ctx context.Context) error {
+	var keys []string
+
+	iter := c.redisClient.Scan(ctx, 0, receipt.PaginatedReceiptsKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	err := iter.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return c.redisClient.Del(ctx, keys...).Err()
 }
 
 func (c ReceiptCache) SetPaginatedReceipts(
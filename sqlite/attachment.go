@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+type AttachmentRepository struct {
+	conn *Conn
+}
+
+const attachmentColumns = `
+            id,
+            receipt_id,
+            filename,
+            content_type,
+            size,
+            sha256,
+            created_at
+`
+
+func scanAttachment(row scanner) (*receipt.Attachment, error) {
+	var attachment receipt.Attachment
+	var createdAt int64
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.ReceiptID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	attachment.CreatedAt = time.Unix(createdAt, 0).UTC()
+
+	return &attachment, nil
+}
+
+func (r AttachmentRepository) FindById(ctx context.Context, receiptID, id string) (*receipt.Attachment, error) {
+	query := "SELECT" + attachmentColumns + "FROM attachment WHERE receipt_id = ? AND id = ?"
+	return findAttachment(r.conn.DB.QueryRowContext(ctx, query, receiptID, id))
+}
+
+func (r AttachmentRepository) FindBySHA256(ctx context.Context, receiptID, sha256 string) (*receipt.Attachment, error) {
+	query := "SELECT" + attachmentColumns + "FROM attachment WHERE receipt_id = ? AND sha256 = ?"
+	return findAttachment(r.conn.DB.QueryRowContext(ctx, query, receiptID, sha256))
+}
+
+func (r AttachmentRepository) Create(ctx context.Context, attachment *receipt.Attachment) error {
+	query := `
+        INSERT INTO attachment (` + attachmentColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+	_, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		attachment.ID,
+		attachment.ReceiptID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.SHA256,
+		attachment.CreatedAt.Unix(),
+	)
+
+	return err
+}
+
+// IsReferenced reports whether any attachment has the content sha256.
+func (r AttachmentRepository) IsReferenced(ctx context.Context, sha256 string) (bool, error) {
+	var referenced bool
+	query := "SELECT EXISTS (SELECT 1 FROM attachment WHERE sha256 = ?)"
+	err := r.conn.DB.QueryRowContext(ctx, query, sha256).Scan(&referenced)
+
+	return referenced, err
+}
+
+func findAttachment(row *sql.Row) (*receipt.Attachment, error) {
+	attachment, err := scanAttachment(row)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Attachment not found"}
+		default:
+			return nil, err
+		}
+	}
+
+	return attachment, nil
+}
+
+// findAttachments returns the attachments of the receipt.
+func findAttachments(ctx context.Context, q querier, receiptID string) ([]receipt.Attachment, error) {
+	query := "SELECT" + attachmentColumns + "FROM attachment WHERE receipt_id = ? ORDER BY created_at, rowid"
+	rows, err := q.QueryContext(ctx, query, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []receipt.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+type CampaignRepository struct {
+	conn *Conn
+}
+
+const campaignColumns = `
+            id,
+            name,
+            starts_at,
+            ends_at,
+            retailer,
+            item_keyword,
+            multiplier,
+            bonus,
+            created_at,
+            updated_at
+`
+
+func scanCampaign(row scanner) (receipt.Campaign, error) {
+	var campaign receipt.Campaign
+	var startsAt, endsAt, createdAt, updatedAt int64
+	err := row.Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&startsAt,
+		&endsAt,
+		&campaign.Retailer,
+		&campaign.ItemKeyword,
+		&campaign.Multiplier,
+		&campaign.Bonus,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return receipt.Campaign{}, err
+	}
+
+	campaign.StartsAt = time.Unix(startsAt, 0).UTC()
+	campaign.EndsAt = time.Unix(endsAt, 0).UTC()
+	campaign.CreatedAt = time.Unix(createdAt, 0).UTC()
+	campaign.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+
+	return campaign, nil
+}
+
+func (r CampaignRepository) query(ctx context.Context, query string, args ...any) ([]receipt.Campaign, error) {
+	rows, err := r.conn.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []receipt.Campaign{}
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+func (r CampaignRepository) Find(ctx context.Context) ([]receipt.Campaign, error) {
+	return r.query(ctx, "SELECT"+campaignColumns+"FROM campaign ORDER BY starts_at DESC")
+}
+
+func (r CampaignRepository) FindActive(ctx context.Context, at time.Time) ([]receipt.Campaign, error) {
+	query := "SELECT" + campaignColumns + `FROM campaign
+        WHERE starts_at <= ? AND ends_at > ?
+        ORDER BY starts_at`
+
+	return r.query(ctx, query, at.Unix(), at.Unix())
+}
+
+func (r CampaignRepository) FindById(ctx context.Context, id string) (*receipt.Campaign, error) {
+	row := r.conn.DB.QueryRowContext(ctx, "SELECT"+campaignColumns+"FROM campaign WHERE id = ?", id)
+	campaign, err := scanCampaign(row)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Campaign not found"}
+		default:
+			return nil, err
+		}
+	}
+
+	return &campaign, nil
+}
+
+func (r CampaignRepository) Create(ctx context.Context, campaign *receipt.Campaign) error {
+	query := `
+        INSERT INTO campaign (` + campaignColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	_, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		campaign.ID,
+		campaign.Name,
+		campaign.StartsAt.Unix(),
+		campaign.EndsAt.Unix(),
+		campaign.Retailer,
+		campaign.ItemKeyword,
+		campaign.Multiplier,
+		campaign.Bonus,
+		campaign.CreatedAt.Unix(),
+		campaign.UpdatedAt.Unix(),
+	)
+
+	return err
+}
+
+func (r CampaignRepository) Update(ctx context.Context, campaign *receipt.Campaign) error {
+	query := `
+        UPDATE campaign SET
+            name = ?,
+            starts_at = ?,
+            ends_at = ?,
+            retailer = ?,
+            item_keyword = ?,
+            multiplier = ?,
+            bonus = ?,
+            updated_at = ?
+        WHERE id = ?
+    `
+	result, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		campaign.Name,
+		campaign.StartsAt.Unix(),
+		campaign.EndsAt.Unix(),
+		campaign.Retailer,
+		campaign.ItemKeyword,
+		campaign.Multiplier,
+		campaign.Bonus,
+		campaign.UpdatedAt.Unix(),
+		campaign.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, "Campaign not found")
+}
+
+func (r CampaignRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.conn.DB.ExecContext(ctx, "DELETE FROM campaign WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, "Campaign not found")
+}
+
+// expectAffected turns a statement that changed no rows into a not found
+// error.
+func expectAffected(result sql.Result, notFoundMessage string) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &errs.Error{Code: errs.ENOTFOUND, Message: notFoundMessage}
+	}
+
+	return nil
+}
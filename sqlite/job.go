@@ -0,0 +1,199 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+type JobRepository struct {
+	conn *Conn
+}
+
+const jobColumns = `
+            id,
+            status,
+            input,
+            receipt_id,
+            points,
+            error,
+            details,
+            created_at,
+            updated_at
+`
+
+func scanJob(row scanner) (*receipt.Job, error) {
+	var job receipt.Job
+	var input string
+	var receiptID, details sql.NullString
+	var points sql.NullInt64
+	var createdAt, updatedAt int64
+	err := row.Scan(
+		&job.ID,
+		&job.Status,
+		&input,
+		&receiptID,
+		&points,
+		&job.Error,
+		&details,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(input), &job.Input)
+	if err != nil {
+		return nil, err
+	}
+	if details.Valid {
+		err = json.Unmarshal([]byte(details.String), &job.Details)
+		if err != nil {
+			return nil, err
+		}
+	}
+	job.Points = intPtr(points)
+	job.ReceiptID = receiptID.String
+	job.CreatedAt = time.Unix(createdAt, 0).UTC()
+	job.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+
+	return &job, nil
+}
+
+func (r JobRepository) FindById(ctx context.Context, id string) (*receipt.Job, error) {
+	row := r.conn.DB.QueryRowContext(ctx, "SELECT"+jobColumns+"FROM job WHERE id = ?", id)
+	job, err := scanJob(row)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Job not found"}
+		default:
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+func (r JobRepository) Create(ctx context.Context, job *receipt.Job) error {
+	input, err := json.Marshal(job.Input)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO job (id, status, input, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+    `
+	_, err = r.conn.DB.ExecContext(
+		ctx,
+		query,
+		job.ID,
+		job.Status,
+		string(input),
+		job.CreatedAt.Unix(),
+		job.UpdatedAt.Unix(),
+	)
+
+	return err
+}
+
+// Claim relies on a single statement to pick and mark the job, so no other
+// caller can claim it in between.
+func (r JobRepository) Claim(ctx context.Context) (*receipt.Job, error) {
+	query := `
+        UPDATE job SET status = ?, updated_at = ?
+        WHERE id = (
+            SELECT id FROM job
+            WHERE status = ?
+            ORDER BY created_at, rowid
+            LIMIT 1
+        )
+        RETURNING` + jobColumns
+	row := r.conn.DB.QueryRowContext(
+		ctx,
+		query,
+		receipt.JobRunning,
+		time.Now().UTC().Unix(),
+		receipt.JobQueued,
+	)
+	job, err := scanJob(row)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "No queued job"}
+		default:
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+func (r JobRepository) Finish(ctx context.Context, job *receipt.Job) error {
+	var details sql.NullString
+	if job.Details != nil {
+		b, err := json.Marshal(job.Details)
+		if err != nil {
+			return err
+		}
+		details = sql.NullString{String: string(b), Valid: true}
+	}
+
+	var receiptID sql.NullString
+	if job.ReceiptID != "" {
+		receiptID = sql.NullString{String: job.ReceiptID, Valid: true}
+	}
+
+	query := `
+        UPDATE job SET
+            status = ?,
+            receipt_id = ?,
+            points = ?,
+            error = ?,
+            details = ?,
+            updated_at = ?
+        WHERE id = ?
+    `
+	result, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		job.Status,
+		receiptID,
+		job.Points,
+		job.Error,
+		details,
+		job.UpdatedAt.Unix(),
+		job.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, "Job not found")
+}
+
+func (r JobRepository) Requeue(ctx context.Context) (int, error) {
+	result, err := r.conn.DB.ExecContext(
+		ctx,
+		"UPDATE job SET status = ?, updated_at = ? WHERE status = ?",
+		receipt.JobQueued,
+		time.Now().UTC().Unix(),
+		receipt.JobRunning,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
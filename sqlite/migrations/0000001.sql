@@ -0,0 +1,11 @@
+-- Store money as an INTEGER number of cents instead of REAL.
+
+ALTER TABLE "receipt" ADD COLUMN "total_cents" INTEGER NOT NULL DEFAULT 0;
+UPDATE "receipt" SET "total_cents" = CAST(ROUND("total" * 100) AS INTEGER);
+ALTER TABLE "receipt" DROP COLUMN "total";
+ALTER TABLE "receipt" RENAME COLUMN "total_cents" TO "total";
+
+ALTER TABLE "item" ADD COLUMN "price_cents" INTEGER NOT NULL DEFAULT 0;
+UPDATE "item" SET "price_cents" = CAST(ROUND("price" * 100) AS INTEGER);
+ALTER TABLE "item" DROP COLUMN "price";
+ALTER TABLE "item" RENAME COLUMN "price_cents" TO "price";
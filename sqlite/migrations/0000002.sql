@@ -0,0 +1,6 @@
+-- Record the points and the ruleset version each receipt was scored with.
+-- Existing receipts were scored with the built-in rules, version v1, their
+-- points stay NULL and are computed on read.
+
+ALTER TABLE "receipt" ADD COLUMN "points" INTEGER;
+ALTER TABLE "receipt" ADD COLUMN "ruleset_version" TEXT NOT NULL DEFAULT 'v1';
@@ -0,0 +1,28 @@
+CREATE TABLE "campaign" (
+	"id"           TEXT NOT NULL,
+	"name"         TEXT NOT NULL,
+	"starts_at"    INTEGER NOT NULL,
+	"ends_at"      INTEGER NOT NULL,
+	"retailer"     TEXT NOT NULL DEFAULT '',
+	"item_keyword" TEXT NOT NULL DEFAULT '',
+	"multiplier"   REAL NOT NULL DEFAULT 0,
+	"bonus"        INTEGER NOT NULL DEFAULT 0,
+	"created_at"   INTEGER NOT NULL,
+	"updated_at"   INTEGER NOT NULL,
+
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "campaign_window_idx" ON "campaign" ("starts_at", "ends_at");
+
+-- Campaign points awarded to a receipt. There is no foreign key to campaign
+-- so the record outlives a deleted campaign.
+CREATE TABLE "receipt_campaign" (
+	"receipt_id"    TEXT NOT NULL,
+	"campaign_id"   TEXT NOT NULL,
+	"campaign_name" TEXT NOT NULL,
+	"points"        INTEGER NOT NULL,
+
+	PRIMARY KEY("receipt_id", "campaign_id"),
+	FOREIGN KEY("receipt_id") REFERENCES "receipt"("id")
+);
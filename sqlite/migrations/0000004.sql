@@ -0,0 +1,11 @@
+-- Store the purchase as a single instant, in unix seconds, along with the
+-- timezone it happened in. Existing receipts had no timezone, their date and
+-- time are taken as UTC.
+
+ALTER TABLE "receipt" ADD COLUMN "purchased_at" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "receipt" ADD COLUMN "timezone" TEXT NOT NULL DEFAULT 'UTC';
+UPDATE "receipt" SET "purchased_at" = CAST(strftime('%s', "purchase_date" || ' ' || "purchase_time") AS INTEGER);
+ALTER TABLE "receipt" DROP COLUMN "purchase_date";
+ALTER TABLE "receipt" DROP COLUMN "purchase_time";
+
+CREATE INDEX "receipt_purchased_at" ON "receipt"("purchased_at");
@@ -0,0 +1,4 @@
+-- Record the category of each item. Items stored before categories existed
+-- were never classified and are left as other.
+
+ALTER TABLE "item" ADD COLUMN "category" TEXT NOT NULL DEFAULT 'other';
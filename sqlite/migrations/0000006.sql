@@ -0,0 +1,8 @@
+-- Fingerprint receipts by content to detect duplicates. Receipts stored
+-- before have an empty fingerprint until backfilled, see receiptctl
+-- fingerprint.
+
+ALTER TABLE "receipt" ADD COLUMN "fingerprint" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "receipt" ADD COLUMN "duplicate_of" TEXT;
+
+CREATE INDEX "receipt_fingerprint" ON "receipt"("fingerprint");
@@ -0,0 +1,17 @@
+-- Receipts submitted for asynchronous processing. input is the submitted
+-- receipt as JSON, details the JSON object of field errors of a failed job.
+CREATE TABLE "job" (
+	"id"         TEXT NOT NULL,
+	"status"     TEXT NOT NULL,
+	"input"      TEXT NOT NULL,
+	"receipt_id" TEXT,
+	"points"     INTEGER,
+	"error"      TEXT NOT NULL DEFAULT '',
+	"details"    TEXT,
+	"created_at" INTEGER NOT NULL,
+	"updated_at" INTEGER NOT NULL,
+
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "job_status_idx" ON "job" ("status", "created_at");
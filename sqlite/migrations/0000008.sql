@@ -0,0 +1,12 @@
+-- Tax, discount, tip and fee lines of a receipt, position keeps them in the
+-- order they were submitted.
+CREATE TABLE "receipt_adjustment" (
+	"receipt_id"  TEXT NOT NULL,
+	"position"    INTEGER NOT NULL,
+	"kind"        TEXT NOT NULL,
+	"description" TEXT NOT NULL DEFAULT '',
+	"amount"      REAL NOT NULL,
+
+	PRIMARY KEY("receipt_id", "position"),
+	FOREIGN KEY("receipt_id") REFERENCES "receipt"("id")
+);
@@ -0,0 +1,6 @@
+-- Items of several units. Items stored before are a single unit priced at
+-- their line price.
+ALTER TABLE "item" ADD COLUMN "quantity" INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "item" ADD COLUMN "unit_price" REAL NOT NULL DEFAULT 0;
+
+UPDATE "item" SET "unit_price" = "price";
@@ -0,0 +1,8 @@
+-- When a receipt was created and last updated. Receipts stored before have
+-- no record of it and get the time of the migration.
+ALTER TABLE "receipt" ADD COLUMN "created_at" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "receipt" ADD COLUMN "updated_at" INTEGER NOT NULL DEFAULT 0;
+
+UPDATE "receipt" SET
+	"created_at" = CAST(strftime('%s', 'now') AS INTEGER),
+	"updated_at" = CAST(strftime('%s', 'now') AS INTEGER);
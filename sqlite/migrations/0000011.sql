@@ -0,0 +1,11 @@
+-- Previous versions of amended receipts, receipt holds the JSON of the
+-- receipt as it was and created_at when it was replaced.
+CREATE TABLE "receipt_revision" (
+	"receipt_id" TEXT NOT NULL,
+	"revision"   INTEGER NOT NULL,
+	"receipt"    TEXT NOT NULL,
+	"created_at" INTEGER NOT NULL,
+
+	PRIMARY KEY("receipt_id", "revision"),
+	FOREIGN KEY("receipt_id") REFERENCES "receipt"("id")
+);
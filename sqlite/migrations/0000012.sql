@@ -0,0 +1,4 @@
+-- Soft deleted receipts keep their row, deleted_at is NULL unless the
+-- receipt was deleted.
+ALTER TABLE "receipt" ADD COLUMN "deleted_at" INTEGER;
+ALTER TABLE "receipt" ADD COLUMN "delete_reason" TEXT NOT NULL DEFAULT '';
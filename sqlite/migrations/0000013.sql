@@ -0,0 +1,17 @@
+-- Files attached to receipts, their content is kept in the blob store under
+-- sha256. A receipt holds the same content once.
+CREATE TABLE "attachment" (
+	"id"           TEXT NOT NULL,
+	"receipt_id"   TEXT NOT NULL,
+	"filename"     TEXT NOT NULL DEFAULT '',
+	"content_type" TEXT NOT NULL,
+	"size"         INTEGER NOT NULL,
+	"sha256"       TEXT NOT NULL,
+	"created_at"   INTEGER NOT NULL,
+
+	PRIMARY KEY("id"),
+	UNIQUE("receipt_id", "sha256"),
+	FOREIGN KEY("receipt_id") REFERENCES "receipt"("id")
+);
+
+CREATE INDEX "attachment_sha256_idx" ON "attachment" ("sha256");
@@ -0,0 +1,49 @@
+-- Receipt events waiting to be published to webhook subscriptions. They are
+-- written in the same transaction as the receipt change, dispatched_at is
+-- set once a delivery was created for each subscription.
+CREATE TABLE "outbox_event" (
+	"id"            TEXT NOT NULL,
+	"type"          TEXT NOT NULL,
+	"receipt_id"    TEXT NOT NULL,
+	"payload"       TEXT NOT NULL,
+	"created_at"    INTEGER NOT NULL,
+	"dispatched_at" INTEGER,
+
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "outbox_event_dispatched_idx" ON "outbox_event" ("dispatched_at", "created_at");
+
+-- Endpoints receiving receipt events, events is a space separated list of
+-- event types.
+CREATE TABLE "webhook_subscription" (
+	"id"         TEXT NOT NULL,
+	"url"        TEXT NOT NULL,
+	"events"     TEXT NOT NULL,
+	"secret"     TEXT NOT NULL,
+	"created_at" INTEGER NOT NULL,
+	"updated_at" INTEGER NOT NULL,
+
+	PRIMARY KEY("id")
+);
+
+-- An event to send to a subscription. Pending deliveries are attempted from
+-- next_attempt_at, they end up delivered or dead once out of attempts.
+CREATE TABLE "webhook_delivery" (
+	"id"              TEXT NOT NULL,
+	"subscription_id" TEXT NOT NULL,
+	"event_id"        TEXT NOT NULL,
+	"status"          TEXT NOT NULL,
+	"attempts"        INTEGER NOT NULL DEFAULT 0,
+	"next_attempt_at" INTEGER NOT NULL,
+	"last_error"      TEXT NOT NULL DEFAULT '',
+	"created_at"      INTEGER NOT NULL,
+	"updated_at"      INTEGER NOT NULL,
+
+	PRIMARY KEY("id"),
+	FOREIGN KEY("subscription_id") REFERENCES "webhook_subscription"("id") ON DELETE CASCADE,
+	FOREIGN KEY("event_id") REFERENCES "outbox_event"("id")
+);
+
+CREATE INDEX "webhook_delivery_due_idx" ON "webhook_delivery" ("status", "next_attempt_at");
+CREATE INDEX "webhook_delivery_subscription_idx" ON "webhook_delivery" ("subscription_id", "created_at");
@@ -0,0 +1,8 @@
+-- Field errors hold a list of messages per field, the details of failed
+-- jobs stored before held a single message.
+UPDATE "job"
+SET "details" = (
+	SELECT json_group_object("key", json_array("value"))
+	FROM json_each("job"."details")
+)
+WHERE "details" IS NOT NULL AND json_type("details") = 'object';
@@ -0,0 +1,21 @@
+-- Only one original receipt may have a given fingerprint, the others are its
+-- duplicates. Originals stored twice by concurrent requests before this
+-- index existed are marked as duplicates of the first one.
+
+UPDATE "receipt" SET "duplicate_of" = (
+	SELECT "original"."id" FROM "receipt" AS "original"
+	WHERE "original"."fingerprint" = "receipt"."fingerprint"
+		AND "original"."duplicate_of" IS NULL
+	ORDER BY "original"."rowid"
+	LIMIT 1
+)
+WHERE "duplicate_of" IS NULL
+	AND "fingerprint" <> ''
+	AND "rowid" > (
+		SELECT min("original"."rowid") FROM "receipt" AS "original"
+		WHERE "original"."fingerprint" = "receipt"."fingerprint"
+		    AND "original"."duplicate_of" IS NULL
+	);
+
+CREATE UNIQUE INDEX "receipt_original_fingerprint" ON "receipt"("fingerprint")
+	WHERE "duplicate_of" IS NULL AND "fingerprint" <> '';
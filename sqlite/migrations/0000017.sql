@@ -0,0 +1,19 @@
+-- Adjustment amounts are cents like every other amount, the table is rebuilt
+-- to make the column INTEGER instead of REAL.
+CREATE TABLE "receipt_adjustment_cents" (
+	"receipt_id"  TEXT NOT NULL,
+	"position"    INTEGER NOT NULL,
+	"kind"        TEXT NOT NULL,
+	"description" TEXT NOT NULL DEFAULT '',
+	"amount"      INTEGER NOT NULL,
+
+	PRIMARY KEY("receipt_id", "position"),
+	FOREIGN KEY("receipt_id") REFERENCES "receipt"("id")
+);
+
+INSERT INTO "receipt_adjustment_cents" ("receipt_id", "position", "kind", "description", "amount")
+SELECT "receipt_id", "position", "kind", "description", CAST(ROUND("amount") AS INTEGER)
+FROM "receipt_adjustment";
+
+DROP TABLE "receipt_adjustment";
+ALTER TABLE "receipt_adjustment_cents" RENAME TO "receipt_adjustment";
@@ -0,0 +1,7 @@
+-- Store item unit prices as an INTEGER number of cents instead of REAL, they
+-- were already written in cents.
+
+ALTER TABLE "item" ADD COLUMN "unit_price_cents" INTEGER NOT NULL DEFAULT 0;
+UPDATE "item" SET "unit_price_cents" = CAST(ROUND("unit_price") AS INTEGER);
+ALTER TABLE "item" DROP COLUMN "unit_price";
+ALTER TABLE "item" RENAME COLUMN "unit_price_cents" TO "unit_price";
@@ -3,19 +3,46 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/mattn/go-sqlite3"
+
 	"github.com/gmr458/receipt-processor/errs"
 	"github.com/gmr458/receipt-processor/receipt"
 )
 
+// eachBatchSize is the number of receipts Each loads per query.
+const eachBatchSize = 500
+
+// sortColumns maps the sort values that aren't a column name to their column.
+var sortColumns = map[string]string{
+	"purchase_date": "purchased_at",
+}
+
 type ReceiptRepository struct {
 	conn *Conn
 }
 
+const receiptColumns = `
+            id,
+            retailer,
+            purchased_at,
+            timezone,
+            total,
+            points,
+            ruleset_version,
+            fingerprint,
+            duplicate_of,
+            created_at,
+            updated_at,
+            deleted_at,
+            delete_reason
+`
+
 func (r ReceiptRepository) FindById(ctx context.Context, id string) (*receipt.Receipt, error) {
 	tx, err := r.conn.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -23,27 +50,24 @@ func (r ReceiptRepository) FindById(ctx context.Context, id string) (*receipt.Re
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	queryReceipt := `
-        SELECT
-            id,
-            retailer,
-            purchase_date,
-            purchase_time,
-            total
-        FROM receipt
-        WHERE id = ?
-    `
-	rec := receipt.Receipt{Items: []receipt.Item{}}
-	var timeStr string
-	var dateStr string
-	row := tx.QueryRow(queryReceipt, id)
-	err = row.Scan(
-		&rec.ID,
-		&rec.Retailer,
-		&dateStr,
-		&timeStr,
-		&rec.Total,
-	)
+	rec, err := findReceipt(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// findReceipt loads the receipt and its children within tx.
+func findReceipt(ctx context.Context, tx *sql.Tx, id string) (*receipt.Receipt, error) {
+	queryReceipt := "SELECT" + receiptColumns + "FROM receipt WHERE id = ?"
+	row := tx.QueryRowContext(ctx, queryReceipt, id)
+	rec, err := scanReceipt(row)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -52,107 +76,405 @@ func (r ReceiptRepository) FindById(ctx context.Context, id string) (*receipt.Re
 			return nil, err
 		}
 	}
-	dateParsed, err := time.Parse("2006-01-02", dateStr)
+
+	receipts := []receipt.Receipt{rec}
+	err = attachChildren(ctx, tx, receipts)
 	if err != nil {
 		return nil, err
 	}
-	timeParsed, err := time.Parse("15:04", timeStr)
+
+	return &receipts[0], nil
+}
+
+// FindIdByFingerprint returns the ID of the first receipt stored with the
+// fingerprint, duplicates are never returned.
+func (r ReceiptRepository) FindIdByFingerprint(ctx context.Context, fingerprint string) (string, error) {
+	query := `
+        SELECT id FROM receipt
+        WHERE fingerprint = ? AND duplicate_of IS NULL
+        ORDER BY rowid
+        LIMIT 1
+    `
+	var id string
+	err := r.conn.DB.QueryRowContext(ctx, query, fingerprint).Scan(&id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+		default:
+			return "", err
+		}
+	}
+
+	return id, nil
+}
+
+func (r ReceiptRepository) Create(ctx context.Context, receipt *receipt.Receipt) error {
+	tx, err := r.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = insertReceipt(ctx, tx, receipt)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateBatch inserts every receipt in a single transaction, either all of
+// them are stored or none is.
+func (r ReceiptRepository) CreateBatch(ctx context.Context, receipts []*receipt.Receipt) error {
+	tx, err := r.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rec := range receipts {
+		err = insertReceipt(ctx, tx, rec)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// errDeletedReceipt is returned by Update for a soft deleted receipt.
+var errDeletedReceipt = &errs.Error{
+	Code:    errs.ECONFLICT,
+	Message: "Deleted receipts can't be amended, restore the receipt first",
+}
+
+// Update replaces the stored receipt, its items, adjustments and applied
+// campaigns with receipt. The version replaced is kept as its next revision.
+// Soft deleted receipts can't be updated, that is a conflict error.
+func (r ReceiptRepository) Update(ctx context.Context, rec *receipt.Receipt) error {
+	tx, err := r.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	previous, err := findReceipt(ctx, tx, rec.ID)
+	if err != nil {
+		return err
+	}
+	if previous.DeletedAt != nil {
+		return errDeletedReceipt
+	}
+
+	snapshot, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+
+	queryRevision := `
+        INSERT INTO receipt_revision (receipt_id, revision, receipt, created_at)
+        SELECT ?, coalesce(max(revision), 0) + 1, ?, ?
+        FROM receipt_revision
+        WHERE receipt_id = ?
+    `
+	_, err = tx.ExecContext(ctx, queryRevision, rec.ID, snapshot, rec.UpdatedAt.Unix(), rec.ID)
+	if err != nil {
+		return err
+	}
+
+	queryReceipt := `
+        UPDATE receipt SET
+            retailer = ?,
+            purchased_at = ?,
+            timezone = ?,
+            total = ?,
+            points = ?,
+            ruleset_version = ?,
+            fingerprint = ?,
+            duplicate_of = ?,
+            updated_at = ?
+        WHERE id = ? AND deleted_at IS NULL
+    `
+	result, err := tx.ExecContext(
+		ctx,
+		queryReceipt,
+		rec.Retailer,
+		rec.PurchasedAt.Unix(),
+		rec.Timezone,
+		rec.Total,
+		rec.Points,
+		rec.RulesetVersion,
+		rec.Fingerprint,
+		sql.NullString{String: rec.DuplicateOf, Valid: rec.DuplicateOf != ""},
+		rec.UpdatedAt.Unix(),
+		rec.ID,
+	)
+	if err != nil {
+		return originalFingerprintError(err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errDeletedReceipt
+	}
+
+	for _, table := range []string{"item", "receipt_adjustment", "receipt_campaign"} {
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE receipt_id = ?", rec.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertChildren(ctx, tx, rec)
+	if err != nil {
+		return err
+	}
+
+	err = insertEvent(ctx, tx, receipt.NewEvent(receipt.EventReceiptUpdated, rec, rec.UpdatedAt))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindRevisions returns the previous versions of the receipt, oldest first.
+func (r ReceiptRepository) FindRevisions(ctx context.Context, id string) ([]receipt.Revision, error) {
+	var exists bool
+	err := r.conn.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM receipt WHERE id = ?)", id).Scan(&exists)
 	if err != nil {
 		return nil, err
 	}
-	rec.PurchaseDate = dateParsed
-	rec.PurchaseTime = timeParsed
+	if !exists {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Receipt not found"}
+	}
 
-	queryItems := `
-        SELECT
-            id,
-            short_description,
-            price,
-            receipt_id
-        FROM item
+	query := `
+        SELECT revision, receipt, created_at
+        FROM receipt_revision
         WHERE receipt_id = ?
+        ORDER BY revision
     `
-	rows, err := tx.QueryContext(ctx, queryItems, rec.ID)
+	rows, err := r.conn.DB.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	revisions := []receipt.Revision{}
 	for rows.Next() {
-		var item receipt.Item
-		err = rows.Scan(
-			&item.ID,
-			&item.ShortDescription,
-			&item.Price,
-			&item.ReceiptID,
-		)
+		var revision receipt.Revision
+		var snapshot []byte
+		var createdAt int64
+		err = rows.Scan(&revision.Revision, &snapshot, &createdAt)
 		if err != nil {
 			return nil, err
 		}
 
-		rec.Items = append(rec.Items, item)
+		err = json.Unmarshal(snapshot, &revision.Receipt)
+		if err != nil {
+			return nil, err
+		}
+		revision.SupersededAt = time.Unix(createdAt, 0).UTC()
+		revisions = append(revisions, revision)
 	}
+
 	err = rows.Err()
 	if err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return nil, err
-	}
+	return revisions, nil
+}
+
+// Delete soft deletes the receipt, receipts already deleted aren't found.
+func (r ReceiptRepository) Delete(ctx context.Context, id, reason string, at time.Time) error {
+	query := `
+        UPDATE receipt SET
+            deleted_at = ?,
+            delete_reason = ?,
+            updated_at = ?
+        WHERE id = ? AND deleted_at IS NULL
+    `
 
-	return &rec, nil
+	return r.changeState(ctx, receipt.EventReceiptDeleted, id, "Receipt not found", query, at.Unix(), reason, at.Unix(), id)
 }
 
-func (r ReceiptRepository) Create(ctx context.Context, receipt *receipt.Receipt) error {
+// Restore undoes the soft deletion of the receipt, only deleted receipts are
+// found.
+func (r ReceiptRepository) Restore(ctx context.Context, id string, at time.Time) error {
+	query := `
+        UPDATE receipt SET
+            deleted_at = NULL,
+            delete_reason = '',
+            updated_at = ?
+        WHERE id = ? AND deleted_at IS NOT NULL
+    `
+
+	return r.changeState(ctx, receipt.EventReceiptUpdated, id, "Deleted receipt not found", query, at.Unix(), id)
+}
+
+// changeState runs query, which updates the receipt id, and records the
+// event with the updated receipt. A query that changes nothing is a not found
+// error with notFoundMessage.
+func (r ReceiptRepository) changeState(
+	ctx context.Context,
+	eventType receipt.EventType,
+	id string,
+	notFoundMessage string,
+	query string,
+	args ...any,
+) error {
 	tx, err := r.conn.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	queryReceipt := `
-        INSERT INTO receipt (
-            id,
-            retailer,
-            purchase_date,
-            purchase_time,
-            total
-        ) VALUES (?, ?, ?, ?, ?)
-    `
-	args := []any{
-		receipt.ID,
-		receipt.Retailer,
-		receipt.PurchaseDate.Format("2006-01-02"),
-		receipt.PurchaseTime.Format("15:04"),
-		receipt.Total,
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
 	}
-	_, err = tx.ExecContext(ctx, queryReceipt, args...)
+
+	err = expectAffected(result, notFoundMessage)
 	if err != nil {
 		return err
 	}
 
-	argsItems := make([]any, 0, len(receipt.Items)*4)
-	var queryItems strings.Builder
-	queryItems.Grow(70 + (len(receipt.Items) * 10))
-	queryItems.WriteString("INSERT INTO item (id, short_description, price, receipt_id) VALUES ")
-	for k, v := range receipt.Items {
-		if k > 0 {
-			queryItems.WriteString(",")
-		}
-		queryItems.WriteString("(?,?,?,?)")
-		argsItems = append(argsItems, v.ID, v.ShortDescription, v.Price, receipt.ID)
+	rec, err := findReceipt(ctx, tx, id)
+	if err != nil {
+		return err
 	}
-	_, err = tx.ExecContext(ctx, queryItems.String(), argsItems...)
+
+	err = insertEvent(ctx, tx, receipt.NewEvent(eventType, rec, rec.UpdatedAt))
 	if err != nil {
 		return err
 	}
 
+	return tx.Commit()
+}
+
+// Purge deletes a soft deleted receipt and every row referencing it, it
+// returns the deleted attachments. Duplicates of the receipt are pointed at
+// its original, or the earliest of them becomes the original when the receipt
+// was one.
+func (r ReceiptRepository) Purge(ctx context.Context, id string, at time.Time) ([]receipt.Attachment, error) {
+	tx, err := r.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	purged, err := findReceipt(ctx, tx, id)
+	if err != nil && errs.ErrorCode(err) != errs.ENOTFOUND {
+		return nil, err
+	}
+	if err != nil || purged.DeletedAt == nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Deleted receipt not found"}
+	}
+
+	attachments, err := findAttachments(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []string{
+		"attachment",
+		"receipt_revision",
+		"item",
+		"receipt_adjustment",
+		"receipt_campaign",
+	}
+	for _, table := range tables {
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE receipt_id = ?", id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM receipt WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repointDuplicates(ctx, tx, purged, at)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertEvent(ctx, tx, receipt.NewEvent(receipt.EventReceiptPurged, purged, at))
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// repointDuplicates points the duplicates of the purged receipt at its
+// original. A purged original is replaced by its earliest duplicate. Every
+// receipt changed records an update event.
+func repointDuplicates(ctx context.Context, tx *sql.Tx, purged *receipt.Receipt, at time.Time) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM receipt WHERE duplicate_of = ? ORDER BY rowid", purged.ID)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	original := purged.DuplicateOf
+	if original == "" {
+		original = ids[0]
+	}
+
+	query := "UPDATE receipt SET duplicate_of = nullif(?, id), updated_at = ? WHERE duplicate_of = ?"
+	_, err = tx.ExecContext(ctx, query, original, at.Unix(), purged.ID)
+	if err != nil {
+		return originalFingerprintError(err)
+	}
+
+	for _, id := range ids {
+		rec, err := findReceipt(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = insertEvent(ctx, tx, receipt.NewEvent(receipt.EventReceiptUpdated, rec, at))
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -161,133 +483,541 @@ func (r ReceiptRepository) Find(
 	ctx context.Context,
 	filters receipt.Filters,
 ) (receipt.PaginatedReceipts, error) {
+	where := "WHERE deleted_at IS NULL"
+	if filters.IncludeDeleted {
+		where = ""
+	}
+
 	var total int
-	err := r.conn.DB.QueryRowContext(ctx, "SELECT count(*) FROM receipt").Scan(&total)
+	err := r.conn.DB.QueryRowContext(ctx, "SELECT count(*) FROM receipt "+where).Scan(&total)
 	if err != nil {
 		return receipt.PaginatedReceipts{}, err
 	}
 
-	queryReceipts := fmt.Sprintf(
-		`SELECT
-            id,
-            retailer,
-            purchase_date,
-            purchase_time,
-            total
-        FROM receipt
+	sortColumn := filters.SortColumn()
+	if column, ok := sortColumns[sortColumn]; ok {
+		sortColumn = column
+	}
+
+	query := fmt.Sprintf(
+		`SELECT`+receiptColumns+`FROM receipt
+        %s
         ORDER BY %s %s
         LIMIT ? OFFSET ?`,
-		filters.SortColumn(),
+		where,
+		sortColumn,
 		filters.SortDirection(),
 	)
 
-	rows, err := r.conn.DB.QueryContext(
-		ctx,
-		queryReceipts,
-		filters.Limit,
-		filters.Offset(),
-	)
+	receipts, err := queryReceipts(ctx, r.conn.DB, filters.Limit, query, filters.Limit, filters.Offset())
 	if err != nil {
 		return receipt.PaginatedReceipts{}, err
 	}
-	defer rows.Close()
 
-	receipts := make([]receipt.Receipt, 0, filters.Limit)
-	receiptIDs := make([]string, 0, filters.Limit)
+	if len(receipts) == 0 {
+		return receipt.PaginatedReceipts{
+			Receipts: receipts,
+			Metadata: nil,
+		}, nil
+	}
 
-	for rows.Next() {
-		var rec receipt.Receipt
-		var timeStr string
-		var dateStr string
-		err = rows.Scan(
-			&rec.ID,
-			&rec.Retailer,
-			&dateStr,
-			&timeStr,
-			&rec.Total,
+	err = attachChildren(ctx, r.conn.DB, receipts)
+	if err != nil {
+		return receipt.PaginatedReceipts{}, err
+	}
+
+	metadata := receipt.CalculateMetadata(total, filters.Page, filters.Limit)
+
+	return receipt.PaginatedReceipts{
+		Receipts: receipts,
+		Metadata: &metadata,
+	}, nil
+}
+
+// Each calls fn with every stored receipt, ordered by id. Receipts are loaded
+// in batches so memory stays bounded however many receipts are stored.
+func (r ReceiptRepository) Each(ctx context.Context, fn func(*receipt.Receipt) error) error {
+	query := "SELECT" + receiptColumns + "FROM receipt WHERE id > ? ORDER BY id LIMIT ?"
+	lastID := ""
+
+	for {
+		receipts, err := queryReceipts(ctx, r.conn.DB, eachBatchSize, query, lastID, eachBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(receipts) == 0 {
+			return nil
+		}
+
+		err = attachChildren(ctx, r.conn.DB, receipts)
+		if err != nil {
+			return err
+		}
+
+		for i := range receipts {
+			err = fn(&receipts[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		lastID = receipts[len(receipts)-1].ID
+	}
+}
+
+// BackfillFingerprints computes the fingerprint of the receipts stored before
+// fingerprints existed and returns how many were updated. Receipts matching
+// an earlier one are marked as its duplicates, their points are kept.
+func (r ReceiptRepository) BackfillFingerprints(ctx context.Context) (int, error) {
+	query := "SELECT" + receiptColumns + "FROM receipt WHERE fingerprint = '' ORDER BY rowid LIMIT ?"
+	updated := 0
+
+	for {
+		receipts, err := queryReceipts(ctx, r.conn.DB, eachBatchSize, query, eachBatchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(receipts) == 0 {
+			return updated, nil
+		}
+
+		err = attachChildren(ctx, r.conn.DB, receipts)
+		if err != nil {
+			return updated, err
+		}
+
+		for _, rec := range receipts {
+			fingerprint := rec.ContentFingerprint()
+
+			originalID, err := r.FindIdByFingerprint(ctx, fingerprint)
+			if err != nil && errs.ErrorCode(err) != errs.ENOTFOUND {
+				return updated, err
+			}
+
+			_, err = r.conn.DB.ExecContext(
+				ctx,
+				"UPDATE receipt SET fingerprint = ?, duplicate_of = ? WHERE id = ?",
+				fingerprint,
+				sql.NullString{String: originalID, Valid: originalID != ""},
+				rec.ID,
+			)
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+}
+
+func insertReceipt(ctx context.Context, tx *sql.Tx, rec *receipt.Receipt) error {
+	queryReceipt := `
+        INSERT INTO receipt (` + receiptColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	args := []any{
+		rec.ID,
+		rec.Retailer,
+		rec.PurchasedAt.Unix(),
+		rec.Timezone,
+		rec.Total,
+		rec.Points,
+		rec.RulesetVersion,
+		rec.Fingerprint,
+		sql.NullString{String: rec.DuplicateOf, Valid: rec.DuplicateOf != ""},
+		rec.CreatedAt.Unix(),
+		rec.UpdatedAt.Unix(),
+		unixPtr(rec.DeletedAt),
+		rec.DeleteReason,
+	}
+	_, err := tx.ExecContext(ctx, queryReceipt, args...)
+	if err != nil {
+		return originalFingerprintError(err)
+	}
+
+	err = insertChildren(ctx, tx, rec)
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, receipt.NewEvent(receipt.EventReceiptProcessed, rec, rec.CreatedAt))
+}
+
+// originalFingerprintError maps violations of the unique index on the
+// fingerprints of original receipts to receipt.ErrOriginalExists.
+func originalFingerprintError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) &&
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "receipt.fingerprint") {
+		return receipt.ErrOriginalExists
+	}
+
+	return err
+}
+
+// insertEvent adds the event to the outbox, see the webhook package.
+func insertEvent(ctx context.Context, tx *sql.Tx, event receipt.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO outbox_event (id, type, receipt_id, payload, created_at) VALUES (?, ?, ?, ?, ?)`,
+		event.ID,
+		event.Type,
+		event.Data.Receipt.ID,
+		payload,
+		event.CreatedAt.Unix(),
+	)
+
+	return err
+}
+
+// insertChildren inserts the items, adjustments and applied campaigns of
+// receipt.
+func insertChildren(ctx context.Context, tx *sql.Tx, receipt *receipt.Receipt) error {
+	argsItems := make([]any, 0, len(receipt.Items)*7)
+	var queryItems strings.Builder
+	queryItems.Grow(110 + (len(receipt.Items) * 16))
+	queryItems.WriteString("INSERT INTO item (id, short_description, price, quantity, unit_price, category, receipt_id) VALUES ")
+	for k, v := range receipt.Items {
+		if k > 0 {
+			queryItems.WriteString(",")
+		}
+		queryItems.WriteString("(?,?,?,?,?,?,?)")
+		argsItems = append(argsItems, v.ID, v.ShortDescription, v.Price, v.Quantity, v.UnitPrice, v.Category, receipt.ID)
+	}
+	_, err := tx.ExecContext(ctx, queryItems.String(), argsItems...)
+	if err != nil {
+		return err
+	}
+
+	for i, adjustment := range receipt.Adjustments {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO receipt_adjustment (receipt_id, position, kind, description, amount) VALUES (?, ?, ?, ?, ?)`,
+			receipt.ID,
+			i,
+			adjustment.Kind,
+			adjustment.Description,
+			adjustment.Amount,
 		)
 		if err != nil {
-			return receipt.PaginatedReceipts{}, err
+			return err
 		}
-		dateParsed, err := time.Parse("2006-01-02", dateStr)
+	}
+
+	for _, campaign := range receipt.Campaigns {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO receipt_campaign (receipt_id, campaign_id, campaign_name, points) VALUES (?, ?, ?, ?)`,
+			receipt.ID,
+			campaign.CampaignID,
+			campaign.Name,
+			campaign.Points,
+		)
 		if err != nil {
-			return receipt.PaginatedReceipts{}, err
+			return err
 		}
-		timeParsed, err := time.Parse("15:04", timeStr)
+	}
+
+	return nil
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanReceipt scans a row selected with receiptColumns. Items, adjustments
+// and campaigns are left empty, see attachChildren.
+func scanReceipt(row scanner) (receipt.Receipt, error) {
+	rec := receipt.Receipt{
+		Items:       []receipt.Item{},
+		Adjustments: []receipt.Adjustment{},
+		Campaigns:   []receipt.AppliedCampaign{},
+	}
+	var purchasedAt, createdAt, updatedAt int64
+	var points, deletedAt sql.NullInt64
+	var duplicateOf sql.NullString
+	err := row.Scan(
+		&rec.ID,
+		&rec.Retailer,
+		&purchasedAt,
+		&rec.Timezone,
+		&rec.Total,
+		&points,
+		&rec.RulesetVersion,
+		&rec.Fingerprint,
+		&duplicateOf,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+		&rec.DeleteReason,
+	)
+	if err != nil {
+		return receipt.Receipt{}, err
+	}
+
+	loc, err := receipt.LoadTimezone(rec.Timezone)
+	if err != nil {
+		return receipt.Receipt{}, err
+	}
+	rec.PurchasedAt = time.Unix(purchasedAt, 0).In(loc)
+	rec.Points = intPtr(points)
+	rec.DuplicateOf = duplicateOf.String
+	rec.CreatedAt = time.Unix(createdAt, 0).UTC()
+	rec.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+	if deletedAt.Valid {
+		t := time.Unix(deletedAt.Int64, 0).UTC()
+		rec.DeletedAt = &t
+	}
+
+	return rec, nil
+}
+
+func queryReceipts(
+	ctx context.Context,
+	q querier,
+	sizeHint int,
+	query string,
+	args ...any,
+) ([]receipt.Receipt, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := make([]receipt.Receipt, 0, sizeHint)
+	for rows.Next() {
+		rec, err := scanReceipt(rows)
 		if err != nil {
-			return receipt.PaginatedReceipts{}, err
+			return nil, err
 		}
-		rec.PurchaseDate = dateParsed
-		rec.PurchaseTime = timeParsed
-		rec.Items = []receipt.Item{}
 		receipts = append(receipts, rec)
-		receiptIDs = append(receiptIDs, rec.ID)
 	}
 
 	err = rows.Err()
 	if err != nil {
-		return receipt.PaginatedReceipts{}, err
+		return nil, err
 	}
 
-	if len(receipts) == 0 {
-		return receipt.PaginatedReceipts{
-			Receipts: receipts,
-			Metadata: nil,
-		}, nil
+	return receipts, nil
+}
+
+// attachChildren loads the items, adjustments and applied campaigns of
+// receipts and summarizes their items by category.
+func attachChildren(ctx context.Context, q querier, receipts []receipt.Receipt) error {
+	receiptIDs := make([]string, len(receipts))
+	for i := range receipts {
+		receiptIDs[i] = receipts[i].ID
 	}
 
-	queryItems := fmt.Sprintf(
+	itemsByReceiptID, err := findItems(ctx, q, receiptIDs)
+	if err != nil {
+		return err
+	}
+
+	adjustmentsByReceiptID, err := findAdjustments(ctx, q, receiptIDs)
+	if err != nil {
+		return err
+	}
+
+	campaignsByReceiptID, err := findAppliedCampaigns(ctx, q, receiptIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range receipts {
+		if items, ok := itemsByReceiptID[receipts[i].ID]; ok {
+			receipts[i].Items = items
+		}
+		if adjustments, ok := adjustmentsByReceiptID[receipts[i].ID]; ok {
+			receipts[i].Adjustments = adjustments
+		}
+		if campaigns, ok := campaignsByReceiptID[receipts[i].ID]; ok {
+			receipts[i].Campaigns = campaigns
+		}
+		receipts[i].Categories = receipt.SummarizeCategories(receipts[i].Items)
+	}
+
+	return nil
+}
+
+func inPlaceholders(ids []string) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	return strings.Repeat("?,", len(ids)-1) + "?", args
+}
+
+func findItems(
+	ctx context.Context,
+	q querier,
+	receiptIDs []string,
+) (map[string][]receipt.Item, error) {
+	placeholders, args := inPlaceholders(receiptIDs)
+	query := fmt.Sprintf(
 		`SELECT
             id,
             short_description,
             price,
+            quantity,
+            unit_price,
+            category,
             receipt_id
         FROM item
         WHERE receipt_id IN (%s)`,
-		strings.Repeat("?,", len(receiptIDs)-1)+"?",
+		placeholders,
 	)
 
-	args := make([]any, len(receiptIDs))
-	for i, id := range receiptIDs {
-		args[i] = id
-	}
-
-	itemRows, err := r.conn.DB.QueryContext(ctx, queryItems, args...)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
-		return receipt.PaginatedReceipts{}, err
+		return nil, err
 	}
-	defer itemRows.Close()
+	defer rows.Close()
 
-	itemsByReceiptID := make(map[string][]receipt.Item, filters.Limit)
-	for itemRows.Next() {
+	itemsByReceiptID := make(map[string][]receipt.Item, len(receiptIDs))
+	for rows.Next() {
 		var item receipt.Item
-		err = itemRows.Scan(
+		err = rows.Scan(
 			&item.ID,
 			&item.ShortDescription,
 			&item.Price,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Category,
 			&item.ReceiptID,
 		)
 		if err != nil {
-			return receipt.PaginatedReceipts{}, err
+			return nil, err
 		}
 		itemsByReceiptID[item.ReceiptID] = append(itemsByReceiptID[item.ReceiptID], item)
 	}
 
-	err = itemRows.Err()
+	err = rows.Err()
 	if err != nil {
-		return receipt.PaginatedReceipts{}, err
+		return nil, err
 	}
 
-	for i := range receipts {
-		if items, ok := itemsByReceiptID[receipts[i].ID]; ok {
-			receipts[i].Items = items
+	return itemsByReceiptID, nil
+}
+
+func findAdjustments(
+	ctx context.Context,
+	q querier,
+	receiptIDs []string,
+) (map[string][]receipt.Adjustment, error) {
+	placeholders, args := inPlaceholders(receiptIDs)
+	query := fmt.Sprintf(
+		`SELECT
+            receipt_id,
+            kind,
+            description,
+            amount
+        FROM receipt_adjustment
+        WHERE receipt_id IN (%s)
+        ORDER BY receipt_id, position`,
+		placeholders,
+	)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustmentsByReceiptID := make(map[string][]receipt.Adjustment, len(receiptIDs))
+	for rows.Next() {
+		var receiptID string
+		var adjustment receipt.Adjustment
+		err = rows.Scan(
+			&receiptID,
+			&adjustment.Kind,
+			&adjustment.Description,
+			&adjustment.Amount,
+		)
+		if err != nil {
+			return nil, err
 		}
+		adjustmentsByReceiptID[receiptID] = append(adjustmentsByReceiptID[receiptID], adjustment)
 	}
 
-	metadata := receipt.CalculateMetadata(total, filters.Page, filters.Limit)
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
 
-	return receipt.PaginatedReceipts{
-		Receipts: receipts,
-		Metadata: &metadata,
-	}, nil
+	return adjustmentsByReceiptID, nil
+}
+
+func findAppliedCampaigns(
+	ctx context.Context,
+	q querier,
+	receiptIDs []string,
+) (map[string][]receipt.AppliedCampaign, error) {
+	placeholders, args := inPlaceholders(receiptIDs)
+	query := fmt.Sprintf(
+		`SELECT
+            receipt_id,
+            campaign_id,
+            campaign_name,
+            points
+        FROM receipt_campaign
+        WHERE receipt_id IN (%s)`,
+		placeholders,
+	)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaignsByReceiptID := make(map[string][]receipt.AppliedCampaign, len(receiptIDs))
+	for rows.Next() {
+		var receiptID string
+		var campaign receipt.AppliedCampaign
+		err = rows.Scan(
+			&receiptID,
+			&campaign.CampaignID,
+			&campaign.Name,
+			&campaign.Points,
+		)
+		if err != nil {
+			return nil, err
+		}
+		campaignsByReceiptID[receiptID] = append(campaignsByReceiptID[receiptID], campaign)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return campaignsByReceiptID, nil
+}
+
+func unixPtr(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+func intPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+
+	v := int(n.Int64)
+	return &v
 }
@@ -2,14 +2,23 @@ package sqlite
 
 import (
 	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/webhook"
 )
 
 type Repository struct {
-	Receipt receipt.ReceiptRepository
+	Receipt    receipt.ReceiptRepository
+	Campaign   receipt.CampaignRepository
+	Job        receipt.JobRepository
+	Attachment receipt.AttachmentRepository
+	Webhook    webhook.Repository
 }
 
 func NewRepository(conn *Conn) Repository {
 	return Repository{
-		Receipt: ReceiptRepository{conn},
+		Receipt:    ReceiptRepository{conn},
+		Campaign:   CampaignRepository{conn},
+		Job:        JobRepository{conn},
+		Attachment: AttachmentRepository{conn},
+		Webhook:    WebhookRepository{conn},
 	}
 }
@@ -0,0 +1,372 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gmr458/receipt-processor/errs"
+	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/webhook"
+)
+
+type WebhookRepository struct {
+	conn *Conn
+}
+
+const subscriptionColumns = `
+            id,
+            url,
+            events,
+            secret,
+            created_at,
+            updated_at
+`
+
+const deliveryColumns = `
+            d.id,
+            d.subscription_id,
+            d.event_id,
+            e.type,
+            d.status,
+            d.attempts,
+            d.next_attempt_at,
+            d.last_error,
+            d.created_at,
+            d.updated_at
+`
+
+func scanSubscription(row scanner) (*webhook.Subscription, error) {
+	var subscription webhook.Subscription
+	var events string
+	var createdAt, updatedAt int64
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&events,
+		&subscription.Secret,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range strings.Fields(events) {
+		subscription.Events = append(subscription.Events, receipt.EventType(eventType))
+	}
+	subscription.CreatedAt = time.Unix(createdAt, 0).UTC()
+	subscription.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+
+	return &subscription, nil
+}
+
+// scanDelivery scans a row selected with deliveryColumns, followed by dest.
+func scanDelivery(row scanner, dest ...any) (*webhook.Delivery, error) {
+	var delivery webhook.Delivery
+	var nextAttemptAt, createdAt, updatedAt int64
+	err := row.Scan(append(
+		[]any{
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Status,
+			&delivery.Attempts,
+			&nextAttemptAt,
+			&delivery.LastError,
+			&createdAt,
+			&updatedAt,
+		},
+		dest...,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+	delivery.NextAttemptAt = time.Unix(nextAttemptAt, 0).UTC()
+	delivery.CreatedAt = time.Unix(createdAt, 0).UTC()
+	delivery.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+
+	return &delivery, nil
+}
+
+func joinEvents(events []receipt.EventType) string {
+	names := make([]string, len(events))
+	for i, eventType := range events {
+		names[i] = string(eventType)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func (r WebhookRepository) FindSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	rows, err := r.conn.DB.QueryContext(ctx, "SELECT"+subscriptionColumns+"FROM webhook_subscription ORDER BY created_at, rowid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []webhook.Subscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r WebhookRepository) FindSubscriptionById(ctx context.Context, id string) (*webhook.Subscription, error) {
+	row := r.conn.DB.QueryRowContext(ctx, "SELECT"+subscriptionColumns+"FROM webhook_subscription WHERE id = ?", id)
+	subscription, err := scanSubscription(row)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Webhook subscription not found"}
+		default:
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
+
+func (r WebhookRepository) CreateSubscription(ctx context.Context, subscription *webhook.Subscription) error {
+	query := `
+        INSERT INTO webhook_subscription (` + subscriptionColumns + `) VALUES (?, ?, ?, ?, ?, ?)
+    `
+	_, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		subscription.ID,
+		subscription.URL,
+		joinEvents(subscription.Events),
+		subscription.Secret,
+		subscription.CreatedAt.Unix(),
+		subscription.UpdatedAt.Unix(),
+	)
+
+	return err
+}
+
+func (r WebhookRepository) UpdateSubscription(ctx context.Context, subscription *webhook.Subscription) error {
+	query := `
+        UPDATE webhook_subscription SET
+            url = ?,
+            events = ?,
+            updated_at = ?
+        WHERE id = ?
+    `
+	result, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		subscription.URL,
+		joinEvents(subscription.Events),
+		subscription.UpdatedAt.Unix(),
+		subscription.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, "Webhook subscription not found")
+}
+
+// DeleteSubscription deletes the subscription along with its deliveries.
+func (r WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.conn.DB.ExecContext(ctx, "DELETE FROM webhook_subscription WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, "Webhook subscription not found")
+}
+
+func (r WebhookRepository) FindDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhook.Delivery, error) {
+	query := `
+        SELECT` + deliveryColumns + `
+        FROM webhook_delivery d
+        JOIN outbox_event e ON e.id = d.event_id
+        WHERE d.subscription_id = ?
+        ORDER BY d.created_at DESC, d.rowid DESC
+        LIMIT ?
+    `
+	rows, err := r.conn.DB.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r WebhookRepository) FindUndispatchedEvents(ctx context.Context, limit int) ([]webhook.OutboxEvent, error) {
+	query := `
+        SELECT id, type, payload, created_at
+        FROM outbox_event
+        WHERE dispatched_at IS NULL
+        ORDER BY created_at, rowid
+        LIMIT ?
+    `
+	rows, err := r.conn.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []webhook.OutboxEvent{}
+	for rows.Next() {
+		var event webhook.OutboxEvent
+		var createdAt int64
+		err = rows.Scan(&event.ID, &event.Type, &event.Payload, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		event.CreatedAt = time.Unix(createdAt, 0).UTC()
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r WebhookRepository) Dispatch(
+	ctx context.Context,
+	eventID string,
+	deliveries []webhook.Delivery,
+	at time.Time,
+) error {
+	tx, err := r.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+        INSERT INTO webhook_delivery (
+            id,
+            subscription_id,
+            event_id,
+            status,
+            attempts,
+            next_attempt_at,
+            last_error,
+            created_at,
+            updated_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	for _, delivery := range deliveries {
+		_, err = tx.ExecContext(
+			ctx,
+			query,
+			delivery.ID,
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt.Unix(),
+			delivery.LastError,
+			delivery.CreatedAt.Unix(),
+			delivery.UpdatedAt.Unix(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE outbox_event SET dispatched_at = ? WHERE id = ?", at.Unix(), eventID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r WebhookRepository) FindDueDeliveries(ctx context.Context, at time.Time, limit int) ([]webhook.Delivery, error) {
+	query := `
+        SELECT` + deliveryColumns + `, s.url, s.secret, e.payload
+        FROM webhook_delivery d
+        JOIN outbox_event e ON e.id = d.event_id
+        JOIN webhook_subscription s ON s.id = d.subscription_id
+        WHERE d.status = ? AND d.next_attempt_at <= ?
+        ORDER BY d.next_attempt_at, d.rowid
+        LIMIT ?
+    `
+	rows, err := r.conn.DB.QueryContext(ctx, query, webhook.DeliveryPending, at.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	for rows.Next() {
+		var url, secret string
+		var payload []byte
+		delivery, err := scanDelivery(rows, &url, &secret, &payload)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL = url
+		delivery.Secret = secret
+		delivery.Payload = payload
+		deliveries = append(deliveries, *delivery)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r WebhookRepository) SaveAttempt(ctx context.Context, delivery *webhook.Delivery) error {
+	query := `
+        UPDATE webhook_delivery SET
+            status = ?,
+            attempts = ?,
+            next_attempt_at = ?,
+            last_error = ?,
+            updated_at = ?
+        WHERE id = ?
+    `
+	_, err := r.conn.DB.ExecContext(
+		ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt.Unix(),
+		delivery.LastError,
+		delivery.UpdatedAt.Unix(),
+		delivery.ID,
+	)
+
+	return err
+}
@@ -1,20 +1,28 @@
 package validator
 
+import (
+	"slices"
+	"strconv"
+)
+
+// Validator collects error messages by field path, a field may have several.
+// Paths of nested fields are built with Field and Index, like items[2].price.
 type Validator struct {
-	Errors map[string]string
+	Errors map[string][]string
 }
 
 func New() *Validator {
-	return &Validator{Errors: make(map[string]string)}
+	return &Validator{Errors: make(map[string][]string)}
 }
 
 func (v *Validator) Ok() bool {
 	return len(v.Errors) == 0
 }
 
+// AddError adds message to the errors of key, unless key already has it.
 func (v *Validator) AddError(key, message string) {
-	if _, exists := v.Errors[key]; !exists {
-		v.Errors[key] = message
+	if !slices.Contains(v.Errors[key], message) {
+		v.Errors[key] = append(v.Errors[key], message)
 	}
 }
 
@@ -23,3 +31,17 @@ func (v *Validator) Check(ok bool, key, message string) {
 		v.AddError(key, message)
 	}
 }
+
+// Field returns the path of the field name of the object at path.
+func Field(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// Index returns the path of the i-th element of the array at path.
+func Index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{Field("", "retailer"), "retailer"},
+		{Index("items", 2), "items[2]"},
+		{Field(Index("items", 2), "price"), "items[2].price"},
+		{Field(Field(Index("rules", 0), "params"), "end"), "rules[0].params.end"},
+	}
+
+	for _, test := range tests {
+		if test.path != test.expected {
+			t.Errorf("expected path %s. got %s", test.expected, test.path)
+		}
+	}
+}
+
+func TestAddError(t *testing.T) {
+	v := New()
+
+	v.Check(true, "total", "total must be greater than 0.00")
+	if !v.Ok() {
+		t.Fatalf("expected no errors. got %v", v.Errors)
+	}
+
+	v.Check(false, "total", "total must be greater than 0.00")
+	v.Check(false, "total", "total should be equal to the sum of all items price")
+	v.Check(false, "total", "total must be greater than 0.00")
+
+	expected := []string{"total must be greater than 0.00", "total should be equal to the sum of all items price"}
+	if !slices.Equal(v.Errors["total"], expected) {
+		t.Errorf("expected errors %q. got %q", expected, v.Errors["total"])
+	}
+}
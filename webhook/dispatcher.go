@@ -0,0 +1,250 @@
+package webhook
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// batchSize is the number of outbox events and due deliveries a Run
+	// handles per query.
+	batchSize = 50
+
+	// maxRetryDelay caps the exponential backoff between attempts.
+	maxRetryDelay = 6 * time.Hour
+)
+
+type Dispatcher struct {
+	repository  Repository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	concurrency int
+	now         func() time.Time
+}
+
+// NewDispatcher returns a Dispatcher that makes up to maxAttempts attempts
+// per delivery, waiting backoff after the first failed attempt and twice as
+// long after each of the next ones. Up to concurrency deliveries are sent at
+// the same time.
+func NewDispatcher(
+	repository Repository,
+	client *http.Client,
+	maxAttempts int,
+	backoff time.Duration,
+	concurrency int,
+) Dispatcher {
+	return Dispatcher{
+		repository,
+		client,
+		maxAttempts,
+		backoff,
+		max(1, concurrency),
+		time.Now,
+	}
+}
+
+// Run creates the deliveries of the outbox events and makes the delivery
+// attempts that are due. It returns the number of attempts made, failed
+// attempts aren't an error.
+func (d *Dispatcher) Run(ctx context.Context) (int, error) {
+	err := d.dispatch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attempts := 0
+	for {
+		deliveries, err := d.repository.FindDueDeliveries(ctx, d.now().UTC(), batchSize)
+		if err != nil {
+			return attempts, err
+		}
+		if len(deliveries) == 0 {
+			return attempts, nil
+		}
+
+		n, err := d.attemptAll(ctx, deliveries)
+		attempts += n
+		if err != nil {
+			return attempts, err
+		}
+	}
+}
+
+// attemptAll makes the attempts of deliveries, up to d.concurrency at a
+// time, and returns how many were stored. No attempt starts once ctx is done.
+func (d *Dispatcher) attemptAll(ctx context.Context, deliveries []Delivery) (int, error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	attempts := 0
+	slots := make(chan struct{}, d.concurrency)
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := d.attempt(ctx, &deliveries[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				firstErr = cmp.Or(firstErr, err)
+				return
+			}
+			attempts++
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return attempts, firstErr
+	}
+
+	return attempts, ctx.Err()
+}
+
+// dispatch creates a delivery of each outbox event for every subscription to
+// its type. Events nobody subscribes to are dispatched without deliveries.
+func (d *Dispatcher) dispatch(ctx context.Context) error {
+	subscriptions, err := d.repository.FindSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		events, err := d.repository.FindUndispatchedEvents(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		now := d.now().UTC().Truncate(time.Second)
+		for _, event := range events {
+			deliveries := []Delivery{}
+			for _, subscription := range subscriptions {
+				if !subscription.Subscribes(event.Type) {
+					continue
+				}
+				deliveries = append(deliveries, Delivery{
+					ID:             uuid.New().String(),
+					SubscriptionID: subscription.ID,
+					EventID:        event.ID,
+					EventType:      event.Type,
+					Status:         DeliveryPending,
+					NextAttemptAt:  now,
+					CreatedAt:      now,
+					UpdatedAt:      now,
+				})
+			}
+
+			err = d.repository.Dispatch(ctx, event.ID, deliveries, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// attempt sends the delivery and stores the outcome. The delivery is
+// retried later if sending fails, unless it is out of attempts. A send
+// interrupted by ctx isn't counted as an attempt, the delivery is retried
+// after the backoff.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) error {
+	now := d.now().UTC()
+	sendErr := d.send(ctx, delivery, now)
+
+	interrupted := sendErr != nil && ctx.Err() != nil
+	if !interrupted {
+		delivery.Attempts++
+	}
+	delivery.UpdatedAt = now.Truncate(time.Second)
+	switch {
+	case sendErr == nil:
+		delivery.Status = DeliveryDelivered
+		delivery.LastError = ""
+	case interrupted:
+		delivery.NextAttemptAt = now.Add(d.backoff).Truncate(time.Second)
+		delivery.LastError = sendErr.Error()
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = DeliveryDead
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(d.retryDelay(delivery.Attempts)).Truncate(time.Second)
+		delivery.LastError = sendErr.Error()
+	}
+
+	// The outcome is stored even if ctx is done, so an interrupted delivery
+	// keeps its backoff.
+	return d.repository.SaveAttempt(context.WithoutCancel(ctx), delivery)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Webhook-Id", delivery.EventID)
+	req.Header.Set("Webhook-Event", string(delivery.EventType))
+	req.Header.Set("Webhook-Signature", Sign(delivery.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Reading some of the body lets the connection be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// retryDelay is how long to wait after the attempts-th failed attempt.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// Sign returns the Webhook-Signature header of a request with body sent at
+// t, see the package documentation.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
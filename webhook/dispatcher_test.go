@@ -0,0 +1,277 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+type memoryRepository struct {
+	Repository
+	mu            sync.Mutex
+	subscriptions []Subscription
+	events        []OutboxEvent
+	deliveries    []*Delivery
+}
+
+func (m *memoryRepository) FindSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *memoryRepository) FindUndispatchedEvents(ctx context.Context, limit int) ([]OutboxEvent, error) {
+	return slices.Clone(m.events[:min(limit, len(m.events))]), nil
+}
+
+func (m *memoryRepository) Dispatch(ctx context.Context, eventID string, deliveries []Delivery, at time.Time) error {
+	for _, delivery := range deliveries {
+		m.deliveries = append(m.deliveries, &delivery)
+	}
+	for i, event := range m.events {
+		if event.ID == eventID {
+			m.events = append(m.events[:i], m.events[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryRepository) FindDueDeliveries(ctx context.Context, at time.Time, limit int) ([]Delivery, error) {
+	due := []Delivery{}
+	for _, delivery := range m.deliveries {
+		if delivery.Status != DeliveryPending || delivery.NextAttemptAt.After(at) || len(due) == limit {
+			continue
+		}
+
+		for _, subscription := range m.subscriptions {
+			if subscription.ID == delivery.SubscriptionID {
+				delivery.URL = subscription.URL
+				delivery.Secret = subscription.Secret
+			}
+		}
+		delivery.Payload = []byte(`{"id":"` + delivery.EventID + `"}`)
+		due = append(due, *delivery)
+	}
+
+	return due, nil
+}
+
+func (m *memoryRepository) SaveAttempt(ctx context.Context, delivery *Delivery) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, saved := range m.deliveries {
+		if saved.ID == delivery.ID {
+			*saved = *delivery
+		}
+	}
+
+	return nil
+}
+
+func TestDispatcherRun(t *testing.T) {
+	type request struct {
+		event     string
+		signature string
+		body      string
+	}
+	requests := map[string]request{}
+	var mu sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests[r.URL.Path] = request{r.Header.Get("Webhook-Event"), r.Header.Get("Webhook-Signature"), string(body)}
+		mu.Unlock()
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	repository := &memoryRepository{
+		subscriptions: []Subscription{
+			{ID: "ok", URL: srv.URL + "/ok", Events: []receipt.EventType{receipt.EventReceiptProcessed}, Secret: "secret-ok"},
+			{ID: "failing", URL: srv.URL + "/failing", Events: receipt.EventTypes, Secret: "secret-failing"},
+		},
+		events: []OutboxEvent{
+			{ID: "e1", Type: receipt.EventReceiptProcessed},
+			{ID: "e2", Type: receipt.EventReceiptDeleted},
+		},
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewDispatcher(repository, srv.Client(), 3, time.Minute, 4)
+	dispatcher.now = func() time.Time { return now }
+
+	attempts, err := dispatcher.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 || len(repository.deliveries) != 3 {
+		t.Fatalf("expected 3 deliveries attempted. got %d deliveries and %d attempts", len(repository.deliveries), attempts)
+	}
+	if len(repository.events) != 0 {
+		t.Errorf("expected every event to be dispatched. got %d left", len(repository.events))
+	}
+
+	first := requests["/ok"]
+	if first.event != string(receipt.EventReceiptProcessed) {
+		t.Errorf("expected Webhook-Event %s. got %s", receipt.EventReceiptProcessed, first.event)
+	}
+	if expected := Sign("secret-ok", now, []byte(first.body)); first.signature != expected {
+		t.Errorf("expected Webhook-Signature %s. got %s", expected, first.signature)
+	}
+
+	tests := []struct {
+		after    time.Duration
+		status   DeliveryStatus
+		attempts int
+		delay    time.Duration
+	}{
+		{0, DeliveryPending, 1, time.Minute},
+		{time.Minute, DeliveryPending, 2, 2 * time.Minute},
+		{2 * time.Minute, DeliveryDead, 3, 0},
+	}
+
+	failed := repository.deliveries[1]
+	for _, test := range tests {
+		if test.after > 0 {
+			now = now.Add(test.after)
+			_, err = dispatcher.Run(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		if failed.Status != test.status || failed.Attempts != test.attempts {
+			t.Errorf("expected status %s after %d attempts. got %s after %d", test.status, test.attempts, failed.Status, failed.Attempts)
+		}
+		if test.status == DeliveryPending && !failed.NextAttemptAt.Equal(now.Add(test.delay)) {
+			t.Errorf("expected next attempt at %s. got %s", now.Add(test.delay), failed.NextAttemptAt)
+		}
+		if failed.LastError != "unexpected status 503" {
+			t.Errorf("expected last error %q. got %q", "unexpected status 503", failed.LastError)
+		}
+	}
+
+	now = now.Add(24 * time.Hour)
+	attempts, err = dispatcher.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 0 {
+		t.Errorf("expected dead deliveries not to be attempted again. got %d attempts", attempts)
+	}
+}
+
+func TestDispatcherConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	repository := &memoryRepository{
+		subscriptions: []Subscription{
+			{ID: "ok", URL: srv.URL, Events: receipt.EventTypes, Secret: "secret"},
+		},
+	}
+	for _, id := range []string{"e1", "e2", "e3", "e4", "e5", "e6"} {
+		repository.events = append(repository.events, OutboxEvent{ID: id, Type: receipt.EventReceiptProcessed})
+	}
+
+	dispatcher := NewDispatcher(repository, srv.Client(), 3, time.Minute, 2)
+	attempts, err := dispatcher.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 6 {
+		t.Errorf("expected 6 attempts. got %d", attempts)
+	}
+	if n := maxInFlight.Load(); n != 2 {
+		t.Errorf("expected 2 deliveries sent at the same time. got %d", n)
+	}
+	for _, delivery := range repository.deliveries {
+		if delivery.Status != DeliveryDelivered {
+			t.Errorf("expected delivery %s to be delivered. got %s", delivery.ID, delivery.Status)
+		}
+	}
+}
+
+func TestDispatcherInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	repository := &memoryRepository{
+		subscriptions: []Subscription{
+			{ID: "ok", URL: srv.URL, Events: receipt.EventTypes, Secret: "secret"},
+		},
+		events: []OutboxEvent{{ID: "e1", Type: receipt.EventReceiptProcessed}},
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewDispatcher(repository, srv.Client(), 3, time.Minute, 1)
+	dispatcher.now = func() time.Time { return now }
+
+	_, err := dispatcher.Run(ctx)
+	if err == nil {
+		t.Fatalf("expected the run to stop with the context")
+	}
+
+	delivery := repository.deliveries[0]
+	if delivery.Status != DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("expected a pending delivery without attempts. got %s after %d", delivery.Status, delivery.Attempts)
+	}
+	if !delivery.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected next attempt at %s. got %s", now.Add(time.Minute), delivery.NextAttemptAt)
+	}
+	if delivery.LastError == "" {
+		t.Errorf("expected the interruption to be recorded")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, 8, 30*time.Second, 1)
+
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{20, maxRetryDelay},
+	}
+
+	for _, test := range tests {
+		if delay := dispatcher.retryDelay(test.attempts); delay != test.delay {
+			t.Errorf("expected a delay of %s after %d attempts. got %s", test.delay, test.attempts, delay)
+		}
+	}
+}
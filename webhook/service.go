@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gmr458/receipt-processor/errs"
+)
+
+// deliveriesLimit is how many of the latest deliveries GetDeliveries
+// returns.
+const deliveriesLimit = 100
+
+type Service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return Service{
+		repository,
+	}
+}
+
+// Create subscribes the endpoint and generates its secret, the only time
+// the secret is returned.
+func (s *Service) Create(ctx context.Context, dto SubscriptionDTO) (*Subscription, error) {
+	isValid, errors := dto.IsValid()
+	if !isValid {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	subscription := &Subscription{
+		ID:        uuid.New().String(),
+		URL:       dto.URL,
+		Events:    dto.Events,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = s.repository.CreateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) Update(ctx context.Context, id string, dto SubscriptionDTO) (*Subscription, error) {
+	subscription, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	isValid, errors := dto.IsValid()
+	if !isValid {
+		return nil, &errs.Error{
+			Code:    errs.EINVALID,
+			Message: "Invalid field/s",
+			Details: errors,
+		}
+	}
+
+	subscription.URL = dto.URL
+	subscription.Events = dto.Events
+	subscription.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	err = s.repository.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) GetById(ctx context.Context, id string) (*Subscription, error) {
+	err := uuid.Validate(id)
+	if err != nil {
+		return nil, &errs.Error{Code: errs.ENOTFOUND, Message: "Webhook subscription not found"}
+	}
+
+	subscription, err := s.repository.FindSubscriptionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+func (s *Service) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subscriptions, err := s.repository.FindSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+// Delete unsubscribes the endpoint, its pending deliveries are dropped.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	err := uuid.Validate(id)
+	if err != nil {
+		return &errs.Error{Code: errs.ENOTFOUND, Message: "Webhook subscription not found"}
+	}
+
+	return s.repository.DeleteSubscription(ctx, id)
+}
+
+// GetDeliveries returns the latest deliveries to the subscription, newest
+// first, dead deliveries included.
+func (s *Service) GetDeliveries(ctx context.Context, id string) ([]Delivery, error) {
+	_, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.FindDeliveries(ctx, id, deliveriesLimit)
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,165 @@
+// Package webhook publishes receipt events to subscribed endpoints.
+//
+// Repositories write every receipt.Event to an outbox in the transaction
+// that changes the receipt. A Dispatcher turns each outbox event into a
+// delivery per subscription to its type, then POSTs the event as JSON.
+// Deliveries are at least once, receivers can tell retries apart by the
+// Webhook-Id header, the event ID.
+//
+// Each request is signed with the subscription's secret. The
+// Webhook-Signature header holds the Unix time of the attempt and the hex
+// encoded HMAC-SHA256 of the time, a dot and the body:
+//
+//	Webhook-Signature: t=1700000000,v1=5257a869e7ec...
+//
+// Failed attempts, a transport error or a non 2xx status, are retried with
+// exponential backoff. A delivery out of attempts is dead and is no longer
+// retried.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gmr458/receipt-processor/receipt"
+	"github.com/gmr458/receipt-processor/validator"
+)
+
+// Subscription is an endpoint receiving the receipt events of Events.
+type Subscription struct {
+	ID     string              `json:"id"`
+	URL    string              `json:"url"`
+	Events []receipt.EventType `json:"events"`
+
+	// Secret signs the deliveries, it is only returned when the subscription
+	// is created.
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Subscribes reports whether the subscription receives events of eventType.
+func (s Subscription) Subscribes(eventType receipt.EventType) bool {
+	return slices.Contains(s.Events, eventType)
+}
+
+type SubscriptionDTO struct {
+	URL    string              `json:"url"`
+	Events []receipt.EventType `json:"events"`
+}
+
+func (dto SubscriptionDTO) IsValid() (bool, map[string][]string) {
+	v := validator.New()
+
+	dto.ValidateURL(v)
+	dto.ValidateEvents(v)
+
+	return v.Ok(), v.Errors
+}
+
+func (dto SubscriptionDTO) ValidateURL(v *validator.Validator) {
+	const key = "url"
+	const maxLen = 2048
+
+	u, err := url.Parse(dto.URL)
+	v.Check(
+		err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		key,
+		"url must be an absolute http or https URL",
+	)
+	v.Check(len(dto.URL) <= maxLen, key, fmt.Sprintf("url max length is %d characters", maxLen))
+}
+
+func (dto SubscriptionDTO) ValidateEvents(v *validator.Validator) {
+	const key = "events"
+
+	v.Check(len(dto.Events) != 0, key, "events cannot be empty")
+
+	for i, eventType := range dto.Events {
+		path := validator.Index(key, i)
+		v.Check(
+			eventType.IsValid(),
+			path,
+			fmt.Sprintf("unknown event type %q, it should be one of: %s", eventType, eventTypeNames()),
+		)
+		v.Check(!slices.Contains(dto.Events[:i], eventType), path, fmt.Sprintf("event type %q is repeated", eventType))
+	}
+}
+
+func eventTypeNames() string {
+	names := make([]string, len(receipt.EventTypes))
+	for i, eventType := range receipt.EventTypes {
+		names[i] = string(eventType)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// Delivery is an event sent to a subscription. Pending deliveries are
+// attempted from NextAttemptAt, LastError is why the last attempt failed.
+type Delivery struct {
+	ID             string            `json:"id"`
+	SubscriptionID string            `json:"subscriptionID"`
+	EventID        string            `json:"eventID"`
+	EventType      receipt.EventType `json:"eventType"`
+	Status         DeliveryStatus    `json:"status"`
+	Attempts       int               `json:"attempts"`
+	NextAttemptAt  time.Time         `json:"nextAttemptAt"`
+	LastError      string            `json:"lastError,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+
+	// URL, Secret and Payload are what a due delivery is sent with.
+	URL     string `json:"-"`
+	Secret  string `json:"-"`
+	Payload []byte `json:"-"`
+}
+
+// OutboxEvent is a receipt.Event waiting for its deliveries, Payload is the
+// event as JSON.
+type OutboxEvent struct {
+	ID        string
+	Type      receipt.EventType
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type Repository interface {
+	FindSubscriptions(ctx context.Context) ([]Subscription, error)
+	FindSubscriptionById(ctx context.Context, id string) (*Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// FindDeliveries returns the latest deliveries to the subscription,
+	// newest first.
+	FindDeliveries(ctx context.Context, subscriptionID string, limit int) ([]Delivery, error)
+
+	// FindUndispatchedEvents returns the outbox events without deliveries
+	// yet, oldest first.
+	FindUndispatchedEvents(ctx context.Context, limit int) ([]OutboxEvent, error)
+
+	// Dispatch stores the deliveries of the event and marks it dispatched,
+	// both or neither.
+	Dispatch(ctx context.Context, eventID string, deliveries []Delivery, at time.Time) error
+
+	// FindDueDeliveries returns the pending deliveries whose next attempt is
+	// due at, oldest first, along with their URL, Secret and Payload.
+	FindDueDeliveries(ctx context.Context, at time.Time, limit int) ([]Delivery, error)
+
+	// SaveAttempt stores the outcome of an attempt to send the delivery.
+	SaveAttempt(ctx context.Context, delivery *Delivery) error
+}
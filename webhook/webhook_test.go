@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gmr458/receipt-processor/receipt"
+)
+
+func TestSubscriptionDTOIsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		dto     SubscriptionDTO
+		isValid bool
+		errors  []string
+	}{
+		{
+			"valid",
+			SubscriptionDTO{
+				URL:    "https://example.com/hooks/receipts",
+				Events: []receipt.EventType{receipt.EventReceiptProcessed, receipt.EventReceiptDeleted},
+			},
+			true,
+			nil,
+		},
+		{
+			"relative url",
+			SubscriptionDTO{URL: "/hooks", Events: []receipt.EventType{receipt.EventReceiptUpdated}},
+			false,
+			[]string{"url"},
+		},
+		{
+			"unsupported scheme",
+			SubscriptionDTO{URL: "ftp://example.com/hooks", Events: []receipt.EventType{receipt.EventReceiptUpdated}},
+			false,
+			[]string{"url"},
+		},
+		{
+			"url too long",
+			SubscriptionDTO{
+				URL:    "https://example.com/" + strings.Repeat("a", 2048),
+				Events: []receipt.EventType{receipt.EventReceiptUpdated},
+			},
+			false,
+			[]string{"url"},
+		},
+		{
+			"no events",
+			SubscriptionDTO{URL: "https://example.com/hooks"},
+			false,
+			[]string{"events"},
+		},
+		{
+			"unknown event",
+			SubscriptionDTO{URL: "https://example.com/hooks", Events: []receipt.EventType{"receipt.created"}},
+			false,
+			[]string{"events[0]"},
+		},
+		{
+			"repeated event",
+			SubscriptionDTO{
+				URL:    "https://example.com/hooks",
+				Events: []receipt.EventType{receipt.EventReceiptUpdated, receipt.EventReceiptUpdated},
+			},
+			false,
+			[]string{"events[1]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, errors := test.dto.IsValid()
+			if isValid != test.isValid {
+				t.Fatalf("expected isValid to be %t. got %t, errors %v", test.isValid, isValid, errors)
+			}
+			if len(errors) != len(test.errors) {
+				t.Fatalf("expected %d errors. got %d, %v", len(test.errors), len(errors), errors)
+			}
+			for _, key := range test.errors {
+				if _, ok := errors[key]; !ok {
+					t.Errorf("expected an error for %s. got %v", key, errors)
+				}
+			}
+		})
+	}
+}